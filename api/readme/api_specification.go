@@ -0,0 +1,25 @@
+package readme
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+type APISpecification struct {
+	ID         string    `json:"_id"`
+	Title      string    `json:"title"`
+	Type       string    `json:"type"`
+	Version    string    `json:"version"`
+	Source     string    `json:"source"`
+	LastSynced string    `json:"lastSynced"`
+	Category   *Category `json:"category"`
+}
+
+func specContentType(filename string) string {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".yaml", ".yml":
+		return "application/x-yaml"
+	default:
+		return "application/json"
+	}
+}
@@ -1,7 +1,74 @@
 package readme
 
+import "sync"
+
+const (
+	CategoryTypeGuide     = "guide"
+	CategoryTypeReference = "reference"
+)
+
 type Category struct {
 	ID    string `json:"_id"`
 	Slug  string `json:"slug"`
 	Title string `json:"title"`
+	Type  string `json:"type"`
+	Order int    `json:"order"`
+}
+
+// categoryCache maps category IDs to categories, safe for concurrent use.
+// It is empty until filled with the full list of categories.
+type categoryCache struct {
+	mu   sync.RWMutex
+	cats map[string]*Category
+}
+
+func newCategoryCache() *categoryCache {
+	return &categoryCache{cats: map[string]*Category{}}
+}
+
+// all returns the cached categories, or nil if the cache is not filled.
+func (c *categoryCache) all() []*Category {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if len(c.cats) == 0 {
+		return nil
+	}
+	res := make([]*Category, 0, len(c.cats))
+	for _, v := range c.cats {
+		res = append(res, v)
+	}
+	return res
+}
+
+func (c *categoryCache) get(id string) *Category {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.cats[id]
+}
+
+func (c *categoryCache) fill(cats []*Category) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, cat := range cats {
+		c.cats[cat.ID] = cat
+	}
+}
+
+// update replaces cat if the cache is filled.
+func (c *categoryCache) update(cat *Category) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.cats) > 0 {
+		c.cats[cat.ID] = cat
+	}
+}
+
+func (c *categoryCache) remove(slug string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for id, cat := range c.cats {
+		if cat.Slug == slug {
+			delete(c.cats, id)
+		}
+	}
 }
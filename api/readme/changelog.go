@@ -0,0 +1,19 @@
+package readme
+
+const (
+	ChangelogTypeAdded      = "added"
+	ChangelogTypeFixed      = "fixed"
+	ChangelogTypeImproved   = "improved"
+	ChangelogTypeDeprecated = "deprecated"
+	ChangelogTypeRemoved    = "removed"
+)
+
+type Changelog struct {
+	ID        string `json:"_id"`
+	Slug      string `json:"slug"`
+	Title     string `json:"title"`
+	Type      string `json:"type"`
+	Body      string `json:"body"`
+	Hidden    bool   `json:"hidden"`
+	CreatedAt string `json:"createdAt"`
+}
@@ -2,24 +2,41 @@ package readme
 
 import (
 	"bytes"
+	"context"
 	"encoding/base64"
 	"encoding/json"
-	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
-	"log"
+	"mime/multipart"
 	"net/http"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
 
 	"github.com/TylerBrock/colorjson"
 )
 
 type Client struct {
 	*http.Client
-	Endpoint      string
-	APIKey        string
-	Output        io.Writer
-	categoryCache map[string]*Category
+	Endpoint       string
+	APIKey         string
+	Version        string
+	Output         io.Writer
+	RequestTimeout time.Duration
+	// Retry is the policy for retrying failed requests, nil for no retries.
+	Retry *RetryPolicy
+	// Limiter throttles requests, nil for no client-side throttling.
+	Limiter *RateLimiter
+	// Logger receives the log with the API key redacted, nil for none.
+	Logger Logger
+	// DryRun refuses requests other than GET, recording them to Planned
+	// instead.
+	DryRun        bool
+	dryRun        dryRun
+	categoryCache *categoryCache
 }
 
 func NewClient(APIKey string) *Client {
@@ -27,13 +44,20 @@ func NewClient(APIKey string) *Client {
 		Client:        http.DefaultClient,
 		Endpoint:      "https://dash.readme.com/api/v1/",
 		APIKey:        APIKey,
-		categoryCache: map[string]*Category{},
+		Retry:         DefaultRetryPolicy(),
+		Limiter:       NewRateLimiter(0),
+		Logger:        NewLogger(os.Stderr, LevelInfo),
+		categoryCache: newCategoryCache(),
 	}
 }
 
 func (c *Client) Project() (*Project, error) {
+	return c.ProjectContext(context.Background())
+}
+
+func (c *Client) ProjectContext(ctx context.Context) (*Project, error) {
 	res := &Project{}
-	err := c.request("GET", "", nil, &res)
+	err := c.request(ctx, "GET", "", nil, &res)
 	if err != nil {
 		return nil, err
 	}
@@ -41,43 +65,43 @@ func (c *Client) Project() (*Project, error) {
 }
 
 func (c *Client) Categories() ([]*Category, error) {
-	res := make([]*Category, 0)
-	if len(c.categoryCache) > 0 {
-		for _, v := range c.categoryCache {
-			res = append(res, v)
-		}
-		return res, nil
-	}
-	page := 1
-	for {
-		cats, err := c.categories(page)
-		if err != nil {
-			return nil, err
-		}
-		for _, cat := range cats {
-			c.categoryCache[cat.ID] = cat
-		}
-		res = append(res, cats...)
-		if len(res) < 100 {
-			break
-		}
-		page++
-	}
-	return res, nil
+	return c.CategoriesContext(context.Background())
 }
 
-func (c *Client) categories(page int) ([]*Category, error) {
+func (c *Client) CategoriesContext(ctx context.Context) ([]*Category, error) {
+	if res := c.categoryCache.all(); res != nil {
+		sortCategories(res)
+		return res, nil
+	}
 	res := make([]*Category, 0)
-	err := c.request("GET", fmt.Sprintf("categories?perPage=100&page=%d", page), nil, &res)
+	err := c.ForEachCategoryContext(ctx, func(cat *Category) error {
+		res = append(res, cat)
+		return nil
+	})
 	if err != nil {
 		return nil, err
 	}
+	c.categoryCache.fill(res)
+	sortCategories(res)
 	return res, nil
 }
 
+func sortCategories(cats []*Category) {
+	sort.SliceStable(cats, func(i, j int) bool {
+		if cats[i].Order != cats[j].Order {
+			return cats[i].Order < cats[j].Order
+		}
+		return cats[i].Slug < cats[j].Slug
+	})
+}
+
 func (c *Client) Category(category string) (*Category, error) {
+	return c.CategoryContext(context.Background(), category)
+}
+
+func (c *Client) CategoryContext(ctx context.Context, category string) (*Category, error) {
 	res := &Category{}
-	err := c.request("GET", fmt.Sprintf("categories/%s", category), nil, &res)
+	err := c.request(ctx, "GET", fmt.Sprintf("categories/%s", category), nil, &res)
 	if err != nil {
 		return nil, err
 	}
@@ -85,31 +109,143 @@ func (c *Client) Category(category string) (*Category, error) {
 }
 
 func (c *Client) CategoryByID(id string) (*Category, error) {
-	if len(c.categoryCache) <= 0 {
-		_, err := c.Categories()
-		if err != nil {
-			return nil, err
-		}
+	return c.CategoryByIDContext(context.Background(), id)
+}
+
+func (c *Client) CategoryByIDContext(ctx context.Context, id string) (*Category, error) {
+	_, err := c.CategoriesContext(ctx)
+	if err != nil {
+		return nil, err
 	}
-	cat := c.categoryCache[id]
+	cat := c.categoryCache.get(id)
 	if cat == nil {
 		return nil, fmt.Errorf("no such category: %s", id)
 	}
 	return cat, nil
 }
 
-func (c *Client) Docs(category string) ([]*Doc, error) {
-	res := make([]*Doc, 0)
-	err := c.request("GET", fmt.Sprintf("categories/%s/docs", category), nil, &res)
+func (c *Client) CreateCategory(cat *Category) (*Category, error) {
+	return c.CreateCategoryContext(context.Background(), cat)
+}
+
+func (c *Client) CreateCategoryContext(ctx context.Context, cat *Category) (*Category, error) {
+	req := &struct {
+		Title string `json:"title"`
+		Type  string `json:"type,omitempty"`
+	}{
+		Title: cat.Title,
+		Type:  cat.Type,
+	}
+	res := &Category{}
+	err := c.request(ctx, "POST", "categories", req, &res)
+	if err != nil {
+		return nil, err
+	}
+	c.categoryCache.update(res)
+	return res, nil
+}
+
+func (c *Client) UpdateCategory(category string, cat *Category) (*Category, error) {
+	return c.UpdateCategoryContext(context.Background(), category, cat)
+}
+
+func (c *Client) UpdateCategoryContext(ctx context.Context, category string, cat *Category) (*Category, error) {
+	req := &struct {
+		Title string `json:"title"`
+		Type  string `json:"type,omitempty"`
+	}{
+		Title: cat.Title,
+		Type:  cat.Type,
+	}
+	res := &Category{}
+	err := c.request(ctx, "PUT", fmt.Sprintf("categories/%s", category), req, &res)
 	if err != nil {
 		return nil, err
 	}
+	c.categoryCache.update(res)
 	return res, nil
 }
 
+func (c *Client) DeleteCategory(category string) error {
+	return c.DeleteCategoryContext(context.Background(), category)
+}
+
+func (c *Client) DeleteCategoryContext(ctx context.Context, category string) error {
+	err := c.request(ctx, "DELETE", fmt.Sprintf("categories/%s", category), nil, nil)
+	if err != nil {
+		return err
+	}
+	c.categoryCache.remove(category)
+	return nil
+}
+
+func (c *Client) CategoryDocs(category string) ([]*Doc, error) {
+	return c.CategoryDocsContext(context.Background(), category)
+}
+
+func (c *Client) CategoryDocsContext(ctx context.Context, category string) ([]*Doc, error) {
+	res := make([]*Doc, 0)
+	err := c.ForEachDocContext(ctx, category, func(doc *Doc) error {
+		res = append(res, doc)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return flattenDocs(res), nil
+}
+
+func (c *Client) Docs(category string) ([]*Doc, error) {
+	return c.DocsContext(context.Background(), category)
+}
+
+func (c *Client) DocsContext(ctx context.Context, category string) ([]*Doc, error) {
+	return c.CategoryDocsContext(ctx, category)
+}
+
 func (c *Client) Doc(doc string) (*Doc, error) {
+	return c.DocContext(context.Background(), doc)
+}
+
+func (c *Client) DocContext(ctx context.Context, doc string) (*Doc, error) {
+	res := &Doc{}
+	err := c.request(ctx, "GET", fmt.Sprintf("docs/%s", doc), nil, &res)
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (c *Client) CreateDoc(cat string, doc *Doc) (*Doc, error) {
+	return c.CreateDocContext(context.Background(), cat, doc)
+}
+
+// CreateDocContext creates doc in category cat, leaving the order to ReadMe
+// unless it is set.
+func (c *Client) CreateDocContext(ctx context.Context, cat string, doc *Doc) (*Doc, error) {
+	req := &struct {
+		Slug      string  `json:"slug,omitempty"`
+		Title     string  `json:"title"`
+		Excerpt   string  `json:"excerpt,omitempty"`
+		Body      string  `json:"body,omitempty"`
+		Category  string  `json:"category"`
+		Hidden    bool    `json:"hidden"`
+		Order     int     `json:"order,omitempty"`
+		Type      string  `json:"type,omitempty"`
+		ParentDoc *string `json:"parentDoc"`
+	}{
+		Slug:      doc.Slug,
+		Title:     doc.Title,
+		Excerpt:   doc.Excerpt,
+		Body:      doc.Body,
+		Category:  cat,
+		Hidden:    doc.Hidden,
+		Order:     doc.Order,
+		Type:      doc.Type,
+		ParentDoc: parentDoc(doc),
+	}
 	res := &Doc{}
-	err := c.request("GET", fmt.Sprintf("docs/%s", doc), nil, &res)
+	err := c.request(ctx, "POST", "docs", req, &res)
 	if err != nil {
 		return nil, err
 	}
@@ -117,28 +253,360 @@ func (c *Client) Doc(doc string) (*Doc, error) {
 }
 
 func (c *Client) UpdateDoc(cat string, doc *Doc) error {
+	return c.UpdateDocContext(context.Background(), cat, doc)
+}
+
+// UpdateDocContext replaces doc, moving it to the top level of the category
+// if it has no parent.
+func (c *Client) UpdateDocContext(ctx context.Context, cat string, doc *Doc) error {
+	return c.request(ctx, "PUT", fmt.Sprintf("docs/%s", doc.Slug), updateDocRequest(cat, doc, ""), nil)
+}
+
+func (c *Client) RenameDoc(cat string, doc *Doc, slug string) (*Doc, error) {
+	return c.RenameDocContext(context.Background(), cat, doc, slug)
+}
+
+// RenameDocContext replaces doc like UpdateDocContext and changes its slug.
+func (c *Client) RenameDocContext(ctx context.Context, cat string, doc *Doc, slug string) (*Doc, error) {
+	res := &Doc{}
+	err := c.request(ctx, "PUT", fmt.Sprintf("docs/%s", doc.Slug), updateDocRequest(cat, doc, slug), &res)
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func updateDocRequest(cat string, doc *Doc, slug string) interface{} {
+	return &struct {
+		Slug      string  `json:"slug,omitempty"`
+		Title     string  `json:"title"`
+		Body      string  `json:"body,omitempty"`
+		Category  string  `json:"category"`
+		Hiddle    bool    `json:"hidden"`
+		Order     int     `json:"order"`
+		Type      string  `json:"type,omitempty"`
+		ParentDoc *string `json:"parentDoc"`
+	}{
+		Slug:      slug,
+		Title:     doc.Title,
+		Body:      doc.Body,
+		Category:  cat,
+		Hiddle:    doc.Hidden,
+		Order:     doc.Order,
+		Type:      doc.Type,
+		ParentDoc: parentDoc(doc),
+	}
+}
+
+// parentDoc returns the parent of doc to send, nil for null.
+func parentDoc(doc *Doc) *string {
+	if doc.ParentDoc == "" {
+		return nil
+	}
+	return &doc.ParentDoc
+}
+
+func (c *Client) DeleteDoc(doc string) error {
+	return c.DeleteDocContext(context.Background(), doc)
+}
+
+func (c *Client) DeleteDocContext(ctx context.Context, doc string) error {
+	return c.request(ctx, "DELETE", fmt.Sprintf("docs/%s", doc), nil, nil)
+}
+
+func (c *Client) Versions() ([]*Version, error) {
+	return c.VersionsContext(context.Background())
+}
+
+func (c *Client) VersionsContext(ctx context.Context) ([]*Version, error) {
+	res := make([]*Version, 0)
+	err := c.request(ctx, "GET", "version", nil, &res)
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (c *Client) CreateVersion(from string, ver *Version) (*Version, error) {
+	return c.CreateVersionContext(context.Background(), from, ver)
+}
+
+func (c *Client) CreateVersionContext(ctx context.Context, from string, ver *Version) (*Version, error) {
 	req := &struct {
+		Version      string `json:"version"`
+		From         string `json:"from"`
+		Codename     string `json:"codename,omitempty"`
+		IsStable     bool   `json:"is_stable"`
+		IsBeta       bool   `json:"is_beta"`
+		IsHidden     bool   `json:"is_hidden"`
+		IsDeprecated bool   `json:"is_deprecated"`
+	}{
+		Version:      ver.Version,
+		From:         from,
+		Codename:     ver.Codename,
+		IsStable:     ver.IsStable,
+		IsBeta:       ver.IsBeta,
+		IsHidden:     ver.IsHidden,
+		IsDeprecated: ver.IsDeprecated,
+	}
+	res := &Version{}
+	err := c.request(ctx, "POST", "version", req, &res)
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (c *Client) UpdateVersion(version string, ver *Version) (*Version, error) {
+	return c.UpdateVersionContext(context.Background(), version, ver)
+}
+
+func (c *Client) UpdateVersionContext(ctx context.Context, version string, ver *Version) (*Version, error) {
+	req := &struct {
+		Version      string `json:"version"`
+		Codename     string `json:"codename,omitempty"`
+		IsStable     bool   `json:"is_stable"`
+		IsBeta       bool   `json:"is_beta"`
+		IsHidden     bool   `json:"is_hidden"`
+		IsDeprecated bool   `json:"is_deprecated"`
+	}{
+		Version:      ver.Version,
+		Codename:     ver.Codename,
+		IsStable:     ver.IsStable,
+		IsBeta:       ver.IsBeta,
+		IsHidden:     ver.IsHidden,
+		IsDeprecated: ver.IsDeprecated,
+	}
+	res := &Version{}
+	err := c.request(ctx, "PUT", fmt.Sprintf("version/%s", version), req, &res)
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (c *Client) DeleteVersion(version string) error {
+	return c.DeleteVersionContext(context.Background(), version)
+}
+
+func (c *Client) DeleteVersionContext(ctx context.Context, version string) error {
+	return c.request(ctx, "DELETE", fmt.Sprintf("version/%s", version), nil, nil)
+}
+
+func (c *Client) Changelogs() ([]*Changelog, error) {
+	return c.ChangelogsContext(context.Background())
+}
+
+func (c *Client) ChangelogsContext(ctx context.Context) ([]*Changelog, error) {
+	res := make([]*Changelog, 0)
+	err := c.ForEachChangelogContext(ctx, func(log *Changelog) error {
+		res = append(res, log)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (c *Client) Changelog(slug string) (*Changelog, error) {
+	return c.ChangelogContext(context.Background(), slug)
+}
+
+func (c *Client) ChangelogContext(ctx context.Context, slug string) (*Changelog, error) {
+	res := &Changelog{}
+	err := c.request(ctx, "GET", fmt.Sprintf("changelogs/%s", slug), nil, &res)
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (c *Client) CreateChangelog(log *Changelog) (*Changelog, error) {
+	return c.CreateChangelogContext(context.Background(), log)
+}
+
+func (c *Client) CreateChangelogContext(ctx context.Context, log *Changelog) (*Changelog, error) {
+	res := &Changelog{}
+	err := c.request(ctx, "POST", "changelogs", changelogRequest(log), &res)
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (c *Client) UpdateChangelog(slug string, log *Changelog) (*Changelog, error) {
+	return c.UpdateChangelogContext(context.Background(), slug, log)
+}
+
+func (c *Client) UpdateChangelogContext(ctx context.Context, slug string, log *Changelog) (*Changelog, error) {
+	res := &Changelog{}
+	err := c.request(ctx, "PUT", fmt.Sprintf("changelogs/%s", slug), changelogRequest(log), &res)
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (c *Client) DeleteChangelog(slug string) error {
+	return c.DeleteChangelogContext(context.Background(), slug)
+}
+
+func (c *Client) DeleteChangelogContext(ctx context.Context, slug string) error {
+	return c.request(ctx, "DELETE", fmt.Sprintf("changelogs/%s", slug), nil, nil)
+}
+
+func changelogRequest(log *Changelog) interface{} {
+	return &struct {
+		Title  string `json:"title"`
+		Type   string `json:"type,omitempty"`
+		Body   string `json:"body"`
+		Hidden bool   `json:"hidden"`
+	}{
+		Title:  log.Title,
+		Type:   log.Type,
+		Body:   log.Body,
+		Hidden: log.Hidden,
+	}
+}
+
+func (c *Client) CustomPages() ([]*CustomPage, error) {
+	return c.CustomPagesContext(context.Background())
+}
+
+func (c *Client) CustomPagesContext(ctx context.Context) ([]*CustomPage, error) {
+	res := make([]*CustomPage, 0)
+	err := c.ForEachCustomPageContext(ctx, func(page *CustomPage) error {
+		res = append(res, page)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (c *Client) CustomPage(slug string) (*CustomPage, error) {
+	return c.CustomPageContext(context.Background(), slug)
+}
+
+func (c *Client) CustomPageContext(ctx context.Context, slug string) (*CustomPage, error) {
+	res := &CustomPage{}
+	err := c.request(ctx, "GET", fmt.Sprintf("custompages/%s", slug), nil, &res)
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (c *Client) CreateCustomPage(page *CustomPage) (*CustomPage, error) {
+	return c.CreateCustomPageContext(context.Background(), page)
+}
+
+func (c *Client) CreateCustomPageContext(ctx context.Context, page *CustomPage) (*CustomPage, error) {
+	res := &CustomPage{}
+	err := c.request(ctx, "POST", "custompages", customPageRequest(page), &res)
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (c *Client) UpdateCustomPage(slug string, page *CustomPage) (*CustomPage, error) {
+	return c.UpdateCustomPageContext(context.Background(), slug, page)
+}
+
+func (c *Client) UpdateCustomPageContext(ctx context.Context, slug string, page *CustomPage) (*CustomPage, error) {
+	res := &CustomPage{}
+	err := c.request(ctx, "PUT", fmt.Sprintf("custompages/%s", slug), customPageRequest(page), &res)
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (c *Client) DeleteCustomPage(slug string) error {
+	return c.DeleteCustomPageContext(context.Background(), slug)
+}
+
+func (c *Client) DeleteCustomPageContext(ctx context.Context, slug string) error {
+	return c.request(ctx, "DELETE", fmt.Sprintf("custompages/%s", slug), nil, nil)
+}
+
+func customPageRequest(page *CustomPage) interface{} {
+	return &struct {
 		Title    string `json:"title"`
-		Body     string `json:"body,omitempty"`
-		Category string `json:"category"`
-		Hiddle   bool   `json:"hidden"`
+		Body     string `json:"body"`
+		HTML     string `json:"html,omitempty"`
+		HTMLMode bool   `json:"htmlmode"`
+		Hidden   bool   `json:"hidden"`
 	}{
-		Title:    doc.Title,
-		Body:     doc.Body,
-		Category: cat,
-		Hiddle:   doc.Hidden,
+		Title:    page.Title,
+		Body:     page.Body,
+		HTML:     page.HTML,
+		HTMLMode: page.HTMLMode,
+		Hidden:   page.Hidden,
+	}
+}
+
+func (c *Client) APISpecifications() ([]*APISpecification, error) {
+	return c.APISpecificationsContext(context.Background())
+}
+
+func (c *Client) APISpecificationsContext(ctx context.Context) ([]*APISpecification, error) {
+	res := make([]*APISpecification, 0)
+	err := c.ForEachAPISpecificationContext(ctx, func(spec *APISpecification) error {
+		res = append(res, spec)
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
-	return c.request("PUT", fmt.Sprintf("docs/%s", doc.Slug), req, nil)
+	return res, nil
+}
+
+func (c *Client) CreateAPISpecification(filename string, spec []byte) (*APISpecification, error) {
+	return c.CreateAPISpecificationContext(context.Background(), filename, spec)
+}
+
+func (c *Client) CreateAPISpecificationContext(ctx context.Context, filename string, spec []byte) (*APISpecification, error) {
+	res := &APISpecification{}
+	err := c.upload(ctx, "POST", "api-specification", "spec", filepath.Base(filename), specContentType(filename), spec, &res)
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (c *Client) UpdateAPISpecification(id, filename string, spec []byte) (*APISpecification, error) {
+	return c.UpdateAPISpecificationContext(context.Background(), id, filename, spec)
+}
+
+func (c *Client) UpdateAPISpecificationContext(ctx context.Context, id, filename string, spec []byte) (*APISpecification, error) {
+	res := &APISpecification{}
+	err := c.upload(ctx, "PUT", fmt.Sprintf("api-specification/%s", id), "spec", filepath.Base(filename), specContentType(filename), spec, &res)
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (c *Client) DeleteAPISpecification(id string) error {
+	return c.DeleteAPISpecificationContext(context.Background(), id)
 }
 
-func (c *Client) request(method, uri string, reqJson interface{}, resJson interface{}) error {
-	var body io.Reader
+func (c *Client) DeleteAPISpecificationContext(ctx context.Context, id string) error {
+	return c.request(ctx, "DELETE", fmt.Sprintf("api-specification/%s", id), nil, nil)
+}
+
+func (c *Client) request(ctx context.Context, method, uri string, reqJson interface{}, resJson interface{}) error {
+	var body []byte
 	if reqJson != nil {
 		data, err := json.Marshal(reqJson)
 		if err != nil {
 			return err
 		}
-		body = bytes.NewBuffer(data)
+		body = data
 		if c.Output != nil {
 			err = c.prettyPrint(data)
 			if err != nil {
@@ -146,48 +614,147 @@ func (c *Client) request(method, uri string, reqJson interface{}, resJson interf
 			}
 		}
 	}
-	req, err := http.NewRequest(method, c.Endpoint+uri, body)
+	_, err := c.send(ctx, method, uri, body, "application/json", resJson)
+	return err
+}
+
+func (c *Client) upload(ctx context.Context, method, uri, field, filename, contentType string, content []byte, resJson interface{}) error {
+	body := &bytes.Buffer{}
+	w := multipart.NewWriter(body)
+	h := make(textproto.MIMEHeader)
+	h.Set("Content-Disposition", fmt.Sprintf(`form-data; name="%s"; filename="%s"`, field, filename))
+	h.Set("Content-Type", contentType)
+	part, err := w.CreatePart(h)
+	if err != nil {
+		return err
+	}
+	_, err = part.Write(content)
+	if err != nil {
+		return err
+	}
+	err = w.Close()
 	if err != nil {
 		return err
 	}
+	_, err = c.send(ctx, method, uri, body.Bytes(), w.FormDataContentType(), resJson)
+	return err
+}
+
+func (c *Client) send(ctx context.Context, method, uri string, body []byte, contentType string, resJson interface{}) (http.Header, error) {
+	if c.DryRun && method != "GET" {
+		return nil, c.plan(method, uri, body, contentType, resJson)
+	}
+	for retry := 0; ; retry++ {
+		if c.Limiter != nil {
+			err := c.Limiter.Wait(ctx)
+			if err != nil {
+				return nil, err
+			}
+		}
+		res, data, err := c.do(ctx, method, uri, body, contentType)
+		if err == nil {
+			if c.Limiter != nil {
+				c.Limiter.observe(res.Header)
+			}
+			if res.StatusCode >= 200 && res.StatusCode < 300 {
+				return res.Header, c.decode(data, resJson)
+			}
+			err = responseError(res, data)
+		}
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		status := 0
+		if res != nil {
+			status = res.StatusCode
+		} else if netErr, ok := err.(*networkError); ok {
+			err = netErr.err
+		} else {
+			return nil, err
+		}
+		if c.Retry == nil || retry >= c.Retry.MaxRetries || !c.Retry.retryable(method, status) {
+			return nil, err
+		}
+		wait := c.Retry.backoff(retry)
+		if status == http.StatusTooManyRequests || status == http.StatusServiceUnavailable {
+			if d := retryAfter(res.Header, time.Now()); d > 0 {
+				wait = d
+			}
+			if status == http.StatusTooManyRequests && c.Limiter != nil {
+				c.Limiter.pause(wait)
+			}
+		}
+		c.logf(LevelInfo, "Retrying %s %s in %s: %s", method, uri, wait, err.Error())
+		err = sleep(ctx, wait)
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (c *Client) do(ctx context.Context, method, uri string, body []byte, contentType string) (*http.Response, []byte, error) {
+	if c.RequestTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.RequestTimeout)
+		defer cancel()
+	}
+	var reader io.Reader
 	if body != nil {
-		req.Header.Set("Content-Type", "application/json")
+		reader = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.Endpoint+uri, reader)
+	if err != nil {
+		return nil, nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", contentType)
 	}
 	req.Header.Set("Accept", "application/json")
+	if c.Version != "" {
+		req.Header.Set("x-readme-version", c.Version)
+	}
 	req.Header.Set("Authorization", "Basic "+base64.RawStdEncoding.EncodeToString([]byte(c.APIKey+":")))
-	log.Printf("Making request: %s %s", req.Method, req.URL.String())
+	c.logf(LevelTrace, "> %s %s", req.Method, req.URL.String())
+	c.traceHeader(">", req.Header)
+	start := time.Now()
 	res, err := c.Do(req)
 	if err != nil {
-		return err
+		c.logf(LevelDebug, "%s %s: %s (%s)", req.Method, req.URL.String(), err.Error(), time.Since(start))
+		return nil, nil, &networkError{err}
 	}
 	defer res.Body.Close()
 	data, err := ioutil.ReadAll(res.Body)
 	if err != nil {
-		return err
+		return nil, nil, &networkError{err}
 	}
-	if c.Output != nil {
+	c.logf(LevelDebug, "%s %s: %s (%s, %d bytes)", req.Method, req.URL.String(), res.Status, time.Since(start), len(data))
+	c.traceHeader("<", res.Header)
+	if c.Output != nil && len(data) > 0 {
 		err = c.prettyPrint(data)
 		if err != nil {
-			return err
-		}
-		// io.Copy(c.Output, bytes.NewBuffer(data))
-	}
-	if res.StatusCode != 200 {
-		readmeErr := &Error{}
-		err = json.Unmarshal(data, readmeErr)
-		if err != nil {
-			return errors.New(res.Status)
-		} else {
-			return readmeErr
+			return nil, nil, err
 		}
 	}
-	if resJson != nil {
-		err = json.Unmarshal(data, resJson)
-		if err != nil {
-			return err
-		}
+	return res, data, nil
+}
+
+func responseError(res *http.Response, data []byte) error {
+	readmeErr := &Error{}
+	// the body may not be JSON, e.g. from a proxy, which still leaves the
+	// status to go by
+	_ = json.Unmarshal(data, readmeErr)
+	readmeErr.StatusCode = res.StatusCode
+	readmeErr.Method = res.Request.Method
+	readmeErr.URL = res.Request.URL.String()
+	readmeErr.RequestID = res.Header.Get("x-request-id")
+	return readmeErr
+}
+
+func (c *Client) decode(data []byte, resJson interface{}) error {
+	if resJson == nil {
+		return nil
 	}
-	return nil
+	return json.Unmarshal(data, resJson)
 }
 
 func (c *Client) prettyPrint(data []byte) error {
@@ -0,0 +1,162 @@
+package readme_test
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/cedricshih/readme/api/readme"
+	"github.com/cedricshih/readme/api/readme/readmetest"
+)
+
+const testAPIKey = "rdme_test"
+
+func newTestClient(t *testing.T) (*readmetest.Server, *readme.Client) {
+	srv := readmetest.NewServer(testAPIKey)
+	ts := httptest.NewServer(srv)
+	t.Cleanup(ts.Close)
+	c := readmetest.NewClient(ts, testAPIKey)
+	c.Logger = nil
+	return srv, c
+}
+
+func TestCategoryCRUD(t *testing.T) {
+	_, c := newTestClient(t)
+	cat, err := c.CreateCategory(&readme.Category{Title: "Getting Started"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cat.Slug != "getting-started" || cat.ID == "" {
+		t.Fatalf("created %+v", cat)
+	}
+	got, err := c.Category(cat.Slug)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.ID != cat.ID {
+		t.Errorf("got ID %s, want %s", got.ID, cat.ID)
+	}
+	_, err = c.UpdateCategory(cat.Slug, &readme.Category{Title: "Basics"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	byID, err := c.CategoryByID(cat.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if byID.Title != "Basics" {
+		t.Errorf("got title %s after update", byID.Title)
+	}
+	err = c.DeleteCategory(byID.Slug)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cats, err := c.Categories()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cats) != 0 {
+		t.Errorf("got %d categories after delete", len(cats))
+	}
+}
+
+func TestDocCRUD(t *testing.T) {
+	srv, c := newTestClient(t)
+	cat := srv.AddCategory("", readme.Category{Title: "Guides"})
+	doc, err := c.CreateDoc(cat.ID, &readme.Doc{Slug: "intro", Title: "Intro", Body: "hello"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if doc.ID == "" || doc.Slug != "intro" || doc.Category != cat.ID {
+		t.Fatalf("created %+v", doc)
+	}
+	child, err := c.CreateDoc(cat.ID, &readme.Doc{Slug: "setup", Title: "Setup", ParentDoc: doc.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc.Body = "hello, world"
+	err = c.UpdateDoc(cat.ID, doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := c.Doc("intro")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Body != "hello, world" {
+		t.Errorf("got body %q after update", got.Body)
+	}
+	docs, err := c.CategoryDocs(cat.Slug)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(docs) != 2 || docs[1].Slug != child.Slug || docs[1].ParentDoc != doc.ID {
+		t.Errorf("got docs %+v", docs)
+	}
+	renamed, err := c.RenameDoc(cat.ID, got, "introduction")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if renamed.Slug != "introduction" {
+		t.Errorf("renamed to %s", renamed.Slug)
+	}
+	err = c.DeleteDoc("introduction")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = c.Doc("introduction")
+	if !readme.IsNotFound(err) {
+		t.Errorf("got %v after delete, want not found", err)
+	}
+}
+
+func TestCreateDocOrder(t *testing.T) {
+	srv, c := newTestClient(t)
+	cat := srv.AddCategory("", readme.Category{Title: "Guides"})
+	tests := []struct {
+		order int
+		want  int
+	}{
+		{0, 999},
+		{3, 3},
+	}
+	for _, tt := range tests {
+		doc, err := c.CreateDoc(cat.ID, &readme.Doc{Title: "Doc", Order: tt.order})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if doc.Order != tt.want {
+			t.Errorf("created with order %d, got %d, want %d", tt.order, doc.Order, tt.want)
+		}
+	}
+}
+
+func TestErrors(t *testing.T) {
+	srv, c := newTestClient(t)
+	_, err := c.Doc("missing")
+	if !readme.IsNotFound(err) {
+		t.Fatalf("got %v, want not found", err)
+	}
+	e := readme.AsError(err)
+	if e.StatusCode != 404 || e.Method != "GET" || e.RequestID == "" || e.Message == "" {
+		t.Errorf("got %+v", e)
+	}
+	if readme.IsUnauthorized(err) {
+		t.Error("not found is unauthorized")
+	}
+
+	c.APIKey = "wrong"
+	_, err = c.Project()
+	if !readme.IsUnauthorized(err) {
+		t.Fatalf("got %v, want unauthorized", err)
+	}
+	if readme.IsNotFound(err) {
+		t.Error("unauthorized is not found")
+	}
+
+	c.APIKey = testAPIKey
+	cat := srv.AddCategory("", readme.Category{Title: "Guides"})
+	_, err = c.CreateDoc(cat.ID, &readme.Doc{Slug: "intro"})
+	if !readme.IsStatus(err, 400) {
+		t.Errorf("got %v for a doc without title, want 400", err)
+	}
+}
@@ -0,0 +1,11 @@
+package readme
+
+type CustomPage struct {
+	ID       string `json:"_id"`
+	Slug     string `json:"slug"`
+	Title    string `json:"title"`
+	Body     string `json:"body"`
+	HTML     string `json:"html"`
+	HTMLMode bool   `json:"htmlmode"`
+	Hidden   bool   `json:"hidden"`
+}
@@ -1,10 +1,43 @@
 package readme
 
+import "sort"
+
+const (
+	DocTypeBasic = "basic"
+	DocTypeLink  = "link"
+	DocTypeError = "error"
+)
+
 type Doc struct {
-	Slug     string `json:"slug"`
-	Category string `json:"category"`
-	Title    string `json:"title"`
-	Excerpt  string `json:"excerpt"`
-	Body     string `json:"body" yaml:"-"`
-	Hidden   bool   `json:"hidden"`
+	ID        string `json:"_id"`
+	Slug      string `json:"slug"`
+	Category  string `json:"category"`
+	Title     string `json:"title"`
+	Excerpt   string `json:"excerpt"`
+	Body      string `json:"body" yaml:"-"`
+	Hidden    bool   `json:"hidden"`
+	Order     int    `json:"order"`
+	Type      string `json:"type"`
+	ParentDoc string `json:"parentDoc"`
+	Children  []*Doc `json:"children,omitempty" yaml:"-"`
+}
+
+// flattenDocs returns docs and their children depth first, siblings sorted
+// by order, filling in the parent of children listed without one.
+func flattenDocs(docs []*Doc) []*Doc {
+	sorted := append([]*Doc(nil), docs...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Order < sorted[j].Order
+	})
+	res := make([]*Doc, 0, len(docs))
+	for _, doc := range sorted {
+		res = append(res, doc)
+		for _, child := range doc.Children {
+			if child.ParentDoc == "" {
+				child.ParentDoc = doc.ID
+			}
+		}
+		res = append(res, flattenDocs(doc.Children)...)
+	}
+	return res
 }
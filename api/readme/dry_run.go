@@ -0,0 +1,95 @@
+package readme
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// PlannedRequest is a request not sent in dry run mode.
+type PlannedRequest struct {
+	Method string
+	URL    string
+	// Body is the JSON body, nil for requests without one or multipart
+	// uploads.
+	Body json.RawMessage
+}
+
+type dryRun struct {
+	mu      sync.Mutex
+	planned []*PlannedRequest
+}
+
+// plan records a request not sent, and echoes its JSON body as the response
+// so callers can carry on as if ReadMe accepted it unchanged. Creates are
+// given the ID and slug ReadMe would assign, with an ID marking them as
+// planned.
+func (c *Client) plan(method, uri string, body []byte, contentType string, resJson interface{}) error {
+	req := &PlannedRequest{
+		Method: method,
+		URL:    c.Endpoint + uri,
+	}
+	if body != nil && strings.HasPrefix(contentType, "application/json") {
+		req.Body = append(req.Body, body...)
+	}
+	c.dryRun.mu.Lock()
+	c.dryRun.planned = append(c.dryRun.planned, req)
+	n := len(c.dryRun.planned)
+	c.dryRun.mu.Unlock()
+	if req.Body == nil {
+		return nil
+	}
+	res := []byte(req.Body)
+	if method == "POST" {
+		var err error
+		res, err = plannedCreate(req.Body, n)
+		if err != nil {
+			return err
+		}
+	}
+	return c.decode(res, resJson)
+}
+
+// plannedCreate returns body with the fields ReadMe assigns on create added
+// if they are missing: a placeholder ID and a slug made from the title.
+func plannedCreate(body []byte, n int) ([]byte, error) {
+	fields := make(map[string]interface{})
+	err := json.Unmarshal(body, &fields)
+	if err != nil {
+		return nil, err
+	}
+	if id, _ := fields["_id"].(string); id == "" {
+		fields["_id"] = fmt.Sprintf("dry-run-%d", n)
+	}
+	if slug, _ := fields["slug"].(string); slug == "" {
+		if title, ok := fields["title"].(string); ok {
+			fields["slug"] = Slugify(title)
+		}
+	}
+	return json.Marshal(fields)
+}
+
+// Slugify returns the slug ReadMe derives from a title, before making it
+// unique.
+func Slugify(title string) string {
+	var sb strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(title) {
+		if r >= 'a' && r <= 'z' || r >= '0' && r <= '9' {
+			sb.WriteRune(r)
+			dash = false
+		} else if !dash && sb.Len() > 0 {
+			sb.WriteRune('-')
+			dash = true
+		}
+	}
+	return strings.TrimSuffix(sb.String(), "-")
+}
+
+// Planned returns the requests not sent in dry run mode, in order.
+func (c *Client) Planned() []*PlannedRequest {
+	c.dryRun.mu.Lock()
+	defer c.dryRun.mu.Unlock()
+	return append([]*PlannedRequest{}, c.dryRun.planned...)
+}
@@ -1,10 +1,72 @@
 package readme
 
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+const (
+	ErrDocNotFound        = "DOC_NOTFOUND"
+	ErrChangelogNotFound  = "CHANGELOG_NOTFOUND"
+	ErrCustomPageNotFound = "CUSTOMPAGE_NOTFOUND"
+)
+
+// Error is a failed response from ReadMe.
 type Error struct {
-	ErrorCode string `json:"error"`
-	Message   string `json:"message"`
+	StatusCode int    `json:"-"`
+	Method     string `json:"-"`
+	URL        string `json:"-"`
+	RequestID  string `json:"-"`
+	ErrorCode  string `json:"error"`
+	Message    string `json:"message"`
+	Suggestion string `json:"suggestion"`
+	Docs       string `json:"docs"`
+	Help       string `json:"help"`
 }
 
 func (e *Error) Error() string {
-	return e.Message
+	msg := e.Message
+	if msg == "" {
+		msg = fmt.Sprintf("%d %s", e.StatusCode, http.StatusText(e.StatusCode))
+	}
+	if e.Method == "" {
+		return msg
+	}
+	return fmt.Sprintf("%s %s: %s", e.Method, e.URL, msg)
+}
+
+// AsError returns the ReadMe error in the chain of err, or nil.
+func AsError(err error) *Error {
+	var e *Error
+	if errors.As(err, &e) {
+		return e
+	}
+	return nil
+}
+
+func IsErrorCode(err error, code string) bool {
+	e := AsError(err)
+	return e != nil && e.ErrorCode == code
+}
+
+func IsStatus(err error, status int) bool {
+	e := AsError(err)
+	return e != nil && e.StatusCode == status
+}
+
+func IsNotFound(err error) bool {
+	return IsStatus(err, http.StatusNotFound)
+}
+
+func IsUnauthorized(err error) bool {
+	return IsStatus(err, http.StatusUnauthorized)
+}
+
+func IsForbidden(err error) bool {
+	return IsStatus(err, http.StatusForbidden)
+}
+
+func IsRateLimited(err error) bool {
+	return IsStatus(err, http.StatusTooManyRequests)
 }
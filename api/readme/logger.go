@@ -0,0 +1,85 @@
+package readme
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+type Level int
+
+const (
+	// LevelError logs nothing but failures.
+	LevelError Level = iota
+	// LevelInfo logs notable events such as retries.
+	LevelInfo
+	// LevelDebug logs every request with its status and timing.
+	LevelDebug
+	// LevelTrace logs HTTP headers of every request and response as well.
+	LevelTrace
+)
+
+// Logger receives the log of a Client.
+type Logger interface {
+	Logf(level Level, format string, args ...interface{})
+}
+
+// LevelLogger is a Logger writing messages up to Level to a log.Logger.
+type LevelLogger struct {
+	*log.Logger
+	Level Level
+}
+
+func NewLogger(w io.Writer, level Level) *LevelLogger {
+	return &LevelLogger{
+		Logger: log.New(w, "", log.LstdFlags),
+		Level:  level,
+	}
+}
+
+func (l *LevelLogger) Logf(level Level, format string, args ...interface{}) {
+	if level > l.Level {
+		return
+	}
+	l.Printf(format, args...)
+}
+
+// Redact hides a secret such as an API key, keeping its last 4 characters
+// when it is long enough to tell keys apart.
+func Redact(secret string) string {
+	if len(secret) < 12 {
+		return "****"
+	}
+	return "****" + secret[len(secret)-4:]
+}
+
+func (c *Client) logf(level Level, format string, args ...interface{}) {
+	if c.Logger == nil {
+		return
+	}
+	if c.APIKey == "" {
+		c.Logger.Logf(level, format, args...)
+		return
+	}
+	msg := strings.ReplaceAll(fmt.Sprintf(format, args...), c.APIKey, Redact(c.APIKey))
+	c.Logger.Logf(level, "%s", msg)
+}
+
+// traceHeader logs headers sorted by name, with credentials redacted.
+func (c *Client) traceHeader(prefix string, h http.Header) {
+	names := make([]string, 0, len(h))
+	for name := range h {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		value := strings.Join(h[name], ", ")
+		if strings.EqualFold(name, "Authorization") {
+			value = strings.SplitN(value, " ", 2)[0] + " ****"
+		}
+		c.logf(LevelTrace, "%s %s: %s", prefix, name, value)
+	}
+}
@@ -0,0 +1,187 @@
+package readme
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const perPage = 100
+
+// ErrStop may be returned by the callback of a ForEach method to stop the
+// iteration early without failing.
+var ErrStop = errors.New("stop iteration")
+
+// paginate GETs uri page by page, passing the body of each page to fn which
+// returns the number of items on it, until ReadMe reports the last page.
+func (c *Client) paginate(ctx context.Context, uri string, fn func(data []byte) (int, error)) error {
+	sep := "?"
+	if strings.Contains(uri, "?") {
+		sep = "&"
+	}
+	var prev json.RawMessage
+	seen := 0
+	for page := 1; ; page++ {
+		var data json.RawMessage
+		h, err := c.send(ctx, "GET", fmt.Sprintf("%s%sperPage=%d&page=%d", uri, sep, perPage, page), nil, "", &data)
+		if err != nil {
+			return err
+		}
+		if prev != nil && bytes.Equal(prev, data) {
+			// the endpoint ignores paging
+			return nil
+		}
+		n, err := fn(data)
+		if err == ErrStop {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		seen += n
+		if n == 0 || !hasNextPage(h, n, seen) {
+			return nil
+		}
+		prev = data
+	}
+}
+
+// hasNextPage checks the link header first, then x-total-count, and falls
+// back to whether the last page was full.
+func hasNextPage(h http.Header, n, seen int) bool {
+	if link := h.Get("link"); link != "" {
+		return linkURL(link, "next") != ""
+	}
+	if total, err := strconv.Atoi(h.Get("x-total-count")); err == nil {
+		return seen < total
+	}
+	return n >= perPage
+}
+
+// linkURL returns the URL of rel in a link header, e.g.
+// `</categories?page=2>; rel="next", <>; rel="prev"`.
+func linkURL(link, rel string) string {
+	for _, part := range strings.Split(link, ",") {
+		fields := strings.Split(part, ";")
+		if len(fields) < 2 {
+			continue
+		}
+		u := strings.Trim(strings.TrimSpace(fields[0]), "<>")
+		for _, f := range fields[1:] {
+			if strings.TrimSpace(f) == fmt.Sprintf(`rel="%s"`, rel) {
+				return u
+			}
+		}
+	}
+	return ""
+}
+
+func (c *Client) ForEachCategory(fn func(*Category) error) error {
+	return c.ForEachCategoryContext(context.Background(), fn)
+}
+
+func (c *Client) ForEachCategoryContext(ctx context.Context, fn func(*Category) error) error {
+	return c.paginate(ctx, "categories", func(data []byte) (int, error) {
+		page := make([]*Category, 0)
+		err := json.Unmarshal(data, &page)
+		if err != nil {
+			return 0, err
+		}
+		for _, cat := range page {
+			err = fn(cat)
+			if err != nil {
+				return 0, err
+			}
+		}
+		return len(page), nil
+	})
+}
+
+func (c *Client) ForEachDoc(category string, fn func(*Doc) error) error {
+	return c.ForEachDocContext(context.Background(), category, fn)
+}
+
+func (c *Client) ForEachDocContext(ctx context.Context, category string, fn func(*Doc) error) error {
+	return c.paginate(ctx, fmt.Sprintf("categories/%s/docs", category), func(data []byte) (int, error) {
+		page := make([]*Doc, 0)
+		err := json.Unmarshal(data, &page)
+		if err != nil {
+			return 0, err
+		}
+		for _, doc := range page {
+			err = fn(doc)
+			if err != nil {
+				return 0, err
+			}
+		}
+		return len(page), nil
+	})
+}
+
+func (c *Client) ForEachChangelog(fn func(*Changelog) error) error {
+	return c.ForEachChangelogContext(context.Background(), fn)
+}
+
+func (c *Client) ForEachChangelogContext(ctx context.Context, fn func(*Changelog) error) error {
+	return c.paginate(ctx, "changelogs", func(data []byte) (int, error) {
+		page := make([]*Changelog, 0)
+		err := json.Unmarshal(data, &page)
+		if err != nil {
+			return 0, err
+		}
+		for _, log := range page {
+			err = fn(log)
+			if err != nil {
+				return 0, err
+			}
+		}
+		return len(page), nil
+	})
+}
+
+func (c *Client) ForEachCustomPage(fn func(*CustomPage) error) error {
+	return c.ForEachCustomPageContext(context.Background(), fn)
+}
+
+func (c *Client) ForEachCustomPageContext(ctx context.Context, fn func(*CustomPage) error) error {
+	return c.paginate(ctx, "custompages", func(data []byte) (int, error) {
+		page := make([]*CustomPage, 0)
+		err := json.Unmarshal(data, &page)
+		if err != nil {
+			return 0, err
+		}
+		for _, p := range page {
+			err = fn(p)
+			if err != nil {
+				return 0, err
+			}
+		}
+		return len(page), nil
+	})
+}
+
+func (c *Client) ForEachAPISpecification(fn func(*APISpecification) error) error {
+	return c.ForEachAPISpecificationContext(context.Background(), fn)
+}
+
+func (c *Client) ForEachAPISpecificationContext(ctx context.Context, fn func(*APISpecification) error) error {
+	return c.paginate(ctx, "api-specification", func(data []byte) (int, error) {
+		page := make([]*APISpecification, 0)
+		err := json.Unmarshal(data, &page)
+		if err != nil {
+			return 0, err
+		}
+		for _, spec := range page {
+			err = fn(spec)
+			if err != nil {
+				return 0, err
+			}
+		}
+		return len(page), nil
+	})
+}
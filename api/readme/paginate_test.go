@@ -0,0 +1,101 @@
+package readme_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/cedricshih/readme/api/readme"
+	"github.com/cedricshih/readme/api/readme/readmetest"
+)
+
+func countRequests(srv *readmetest.Server, req string) int {
+	n := 0
+	for _, r := range srv.Requests() {
+		if r == req {
+			n++
+		}
+	}
+	return n
+}
+
+func TestCategoriesPaginate(t *testing.T) {
+	tests := []struct {
+		categories int
+		pages      int
+	}{
+		{0, 1},
+		{99, 1},
+		// the headers tell a full last page from one followed by more
+		{100, 1},
+		{200, 2},
+		{250, 3},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprint(tt.categories), func(t *testing.T) {
+			srv, c := newTestClient(t)
+			for i := 0; i < tt.categories; i++ {
+				srv.AddCategory("", readme.Category{Title: fmt.Sprintf("Category %d", i)})
+			}
+			cats, err := c.Categories()
+			if err != nil {
+				t.Fatal(err)
+			}
+			seen := make(map[string]bool)
+			for _, cat := range cats {
+				seen[cat.Slug] = true
+			}
+			if len(cats) != tt.categories || len(seen) != tt.categories {
+				t.Errorf("got %d categories, %d unique, want %d", len(cats), len(seen), tt.categories)
+			}
+			if n := countRequests(srv, "GET /categories"); n != tt.pages {
+				t.Errorf("got %d page requests, want %d", n, tt.pages)
+			}
+		})
+	}
+}
+
+func TestCategoryDocsPaginate(t *testing.T) {
+	srv, c := newTestClient(t)
+	srv.AddCategory("", readme.Category{Title: "Guides"})
+	for i := 0; i < 230; i++ {
+		srv.AddDoc("", "guides", readme.Doc{Title: fmt.Sprintf("Doc %d", i)})
+	}
+	docs, err := c.CategoryDocs("guides")
+	if err != nil {
+		t.Fatal(err)
+	}
+	seen := make(map[string]bool)
+	for _, d := range docs {
+		seen[d.Slug] = true
+	}
+	if len(docs) != 230 || len(seen) != 230 {
+		t.Errorf("got %d docs, %d unique, want 230", len(docs), len(seen))
+	}
+	if n := countRequests(srv, "GET /categories/guides/docs"); n != 3 {
+		t.Errorf("got %d page requests, want 3", n)
+	}
+}
+
+func TestForEachStop(t *testing.T) {
+	srv, c := newTestClient(t)
+	for i := 0; i < 150; i++ {
+		srv.AddCategory("", readme.Category{Title: fmt.Sprintf("Category %d", i)})
+	}
+	n := 0
+	err := c.ForEachCategory(func(*readme.Category) error {
+		n++
+		if n == 10 {
+			return readme.ErrStop
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 10 {
+		t.Errorf("got %d categories, want 10", n)
+	}
+	if n := countRequests(srv, "GET /categories"); n != 1 {
+		t.Errorf("got %d page requests, want 1", n)
+	}
+}
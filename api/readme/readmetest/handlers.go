@@ -0,0 +1,423 @@
+package readmetest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/cedricshih/readme/api/readme"
+)
+
+const (
+	defaultPerPage = 10
+	maxPerPage     = 100
+)
+
+type categoryRequest struct {
+	Title *string `json:"title"`
+	Type  *string `json:"type"`
+}
+
+type docRequest struct {
+	Slug     *string `json:"slug"`
+	Title    *string `json:"title"`
+	Excerpt  *string `json:"excerpt"`
+	Body     *string `json:"body"`
+	Category *string `json:"category"`
+	Hidden   *bool   `json:"hidden"`
+	Order    *int    `json:"order"`
+	Type     *string `json:"type"`
+	// ParentDoc is kept raw to tell null, which moves a doc to the top
+	// level, from absent
+	ParentDoc json.RawMessage `json:"parentDoc"`
+}
+
+type versionRequest struct {
+	readme.Version
+	From string `json:"from"`
+}
+
+func (s *Server) serveCategories(w http.ResponseWriter, r *http.Request, v *version, path []string) {
+	switch {
+	case len(path) == 0 && r.Method == "GET":
+		cats := make([]interface{}, 0, len(v.categories))
+		for _, c := range sortedCategories(v.categories) {
+			cats = append(cats, c)
+		}
+		writePage(w, r, cats)
+	case len(path) == 0 && r.Method == "POST":
+		req := &categoryRequest{}
+		if !readJSON(w, r, req) {
+			return
+		}
+		if req.Title == nil || *req.Title == "" {
+			writeError(w, http.StatusBadRequest, "CATEGORY_INVALID", "The category couldn't be saved: title is required.")
+			return
+		}
+		cat := readme.Category{Title: *req.Title}
+		if req.Type != nil {
+			cat.Type = *req.Type
+		}
+		writeJSON(w, http.StatusCreated, s.addCategory(v, cat))
+	case len(path) == 0:
+		methodNotAllowed(w, r)
+	default:
+		cat := v.category(path[0])
+		if cat == nil {
+			writeError(w, http.StatusNotFound, "CATEGORY_NOTFOUND", fmt.Sprintf("The category with the slug '%s' couldn't be found.", path[0]))
+			return
+		}
+		if len(path) == 2 && path[1] == "docs" && r.Method == "GET" {
+			docs := v.docTree(cat.ID, "")
+			items := make([]interface{}, 0, len(docs))
+			for _, d := range docs {
+				items = append(items, d)
+			}
+			writePage(w, r, items)
+			return
+		}
+		if len(path) != 1 {
+			writeError(w, http.StatusNotFound, "ENDPOINT_NOTFOUND", fmt.Sprintf("The endpoint '%s' couldn't be found.", r.URL.Path))
+			return
+		}
+		s.serveCategory(w, r, v, cat)
+	}
+}
+
+func (s *Server) serveCategory(w http.ResponseWriter, r *http.Request, v *version, cat *readme.Category) {
+	switch r.Method {
+	case "GET":
+		writeJSON(w, http.StatusOK, cat)
+	case "PUT":
+		req := &categoryRequest{}
+		if !readJSON(w, r, req) {
+			return
+		}
+		if req.Title != nil {
+			cat.Title = *req.Title
+		}
+		if req.Type != nil && *req.Type != "" {
+			cat.Type = *req.Type
+		}
+		writeJSON(w, http.StatusOK, cat)
+	case "DELETE":
+		for _, d := range v.docs {
+			if d.Category == cat.ID {
+				writeError(w, http.StatusBadRequest, "CATEGORY_NOT_EMPTY", fmt.Sprintf("The category '%s' still has docs.", cat.Slug))
+				return
+			}
+		}
+		for i, c := range v.categories {
+			if c == cat {
+				v.categories = append(v.categories[:i], v.categories[i+1:]...)
+				break
+			}
+		}
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		methodNotAllowed(w, r)
+	}
+}
+
+func (s *Server) serveDocs(w http.ResponseWriter, r *http.Request, v *version, path []string) {
+	if len(path) == 0 {
+		if r.Method != "POST" {
+			methodNotAllowed(w, r)
+			return
+		}
+		req := &docRequest{}
+		if !readJSON(w, r, req) {
+			return
+		}
+		if req.Title == nil || *req.Title == "" || req.Category == nil || v.categoryByID(*req.Category) == nil {
+			writeError(w, http.StatusBadRequest, "DOC_INVALID", "The doc couldn't be saved: title and a valid category are required.")
+			return
+		}
+		if !req.validParent(v, "") {
+			writeError(w, http.StatusBadRequest, "DOC_INVALID", "The doc couldn't be saved: parentDoc is invalid.")
+			return
+		}
+		if req.Slug != nil && v.doc(*req.Slug) != nil {
+			writeError(w, http.StatusBadRequest, "DOC_INVALID", fmt.Sprintf("The doc couldn't be saved: slug '%s' is taken.", *req.Slug))
+			return
+		}
+		// ReadMe puts a doc last unless the order is given
+		doc := readme.Doc{Order: 999}
+		req.apply(&doc)
+		writeJSON(w, http.StatusCreated, s.addDoc(v, doc))
+		return
+	}
+	doc := v.doc(path[0])
+	if len(path) != 1 || doc == nil {
+		writeError(w, http.StatusNotFound, readme.ErrDocNotFound, fmt.Sprintf("The doc with the slug '%s' couldn't be found.", path[0]))
+		return
+	}
+	switch r.Method {
+	case "GET":
+		writeJSON(w, http.StatusOK, doc)
+	case "PUT":
+		req := &docRequest{}
+		if !readJSON(w, r, req) {
+			return
+		}
+		if req.Category != nil && v.categoryByID(*req.Category) == nil {
+			writeError(w, http.StatusBadRequest, "DOC_INVALID", "The doc couldn't be saved: category is invalid.")
+			return
+		}
+		if !req.validParent(v, doc.Slug) {
+			writeError(w, http.StatusBadRequest, "DOC_INVALID", "The doc couldn't be saved: parentDoc is invalid.")
+			return
+		}
+		if req.Slug != nil && *req.Slug != doc.Slug && v.doc(*req.Slug) != nil {
+			writeError(w, http.StatusBadRequest, "DOC_INVALID", fmt.Sprintf("The doc couldn't be saved: slug '%s' is taken.", *req.Slug))
+			return
+		}
+		req.apply(doc)
+		writeJSON(w, http.StatusOK, doc)
+	case "DELETE":
+		for i, d := range v.docs {
+			if d == doc {
+				v.docs = append(v.docs[:i], v.docs[i+1:]...)
+				break
+			}
+		}
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		methodNotAllowed(w, r)
+	}
+}
+
+// docTree returns the docs of a category under parent, "" for the top
+// level, with their children nested like ReadMe lists them.
+func (v *version) docTree(cat, parent string) []*readme.Doc {
+	res := make([]*readme.Doc, 0)
+	for _, d := range v.docs {
+		if d.Category != cat || d.ParentDoc != parent {
+			continue
+		}
+		doc := *d
+		doc.Body = ""
+		doc.ParentDoc = ""
+		doc.Children = v.docTree(cat, d.ID)
+		res = append(res, &doc)
+	}
+	sort.SliceStable(res, func(i, j int) bool {
+		return res[i].Order < res[j].Order
+	})
+	return res
+}
+
+func (req *docRequest) apply(doc *readme.Doc) {
+	if req.Slug != nil && *req.Slug != "" {
+		doc.Slug = *req.Slug
+	}
+	if req.Title != nil {
+		doc.Title = *req.Title
+	}
+	if req.Excerpt != nil {
+		doc.Excerpt = *req.Excerpt
+	}
+	if req.Body != nil {
+		doc.Body = *req.Body
+	}
+	if req.Category != nil {
+		doc.Category = *req.Category
+	}
+	if req.Hidden != nil {
+		doc.Hidden = *req.Hidden
+	}
+	if req.Order != nil {
+		doc.Order = *req.Order
+	}
+	if req.Type != nil && *req.Type != "" {
+		doc.Type = *req.Type
+	}
+	if len(req.ParentDoc) > 0 {
+		parent := ""
+		json.Unmarshal(req.ParentDoc, &parent)
+		doc.ParentDoc = parent
+	}
+}
+
+// validParent reports whether the parent in req, if any, is another doc in
+// the same version.
+func (req *docRequest) validParent(v *version, slug string) bool {
+	if len(req.ParentDoc) == 0 {
+		return true
+	}
+	parent := ""
+	json.Unmarshal(req.ParentDoc, &parent)
+	if parent == "" {
+		return true
+	}
+	for _, d := range v.docs {
+		if d.ID == parent {
+			return d.Slug != slug
+		}
+	}
+	return false
+}
+
+func (v *version) categoryByID(id string) *readme.Category {
+	for _, c := range v.categories {
+		if c.ID == id {
+			return c
+		}
+	}
+	return nil
+}
+
+func (s *Server) serveVersions(w http.ResponseWriter, r *http.Request, path []string) {
+	if len(path) == 0 {
+		switch r.Method {
+		case "GET":
+			res := make([]*readme.Version, 0, len(s.versions))
+			for _, v := range s.versions {
+				res = append(res, &v.Version)
+			}
+			writeJSON(w, http.StatusOK, res)
+		case "POST":
+			req := &versionRequest{}
+			if !readJSON(w, r, req) {
+				return
+			}
+			from := s.version(req.From)
+			if req.From == "" || from == nil {
+				writeError(w, http.StatusBadRequest, "VERSION_FORK_EMPTY", "Please specify an existing version to fork from.")
+				return
+			}
+			if req.Version.Version == "" || s.version(req.Version.Version) != nil {
+				writeError(w, http.StatusBadRequest, "VERSION_DUPLICATE", fmt.Sprintf("The version '%s' already exists or is empty.", req.Version.Version))
+				return
+			}
+			writeJSON(w, http.StatusCreated, s.addVersion(req.Version, from).Version)
+		default:
+			methodNotAllowed(w, r)
+		}
+		return
+	}
+	v := s.version(path[0])
+	if len(path) != 1 || v == nil {
+		writeError(w, http.StatusNotFound, "VERSION_NOTFOUND", fmt.Sprintf("The version '%s' couldn't be found.", path[0]))
+		return
+	}
+	switch r.Method {
+	case "GET":
+		writeJSON(w, http.StatusOK, v.Version)
+	case "PUT":
+		req := &versionRequest{Version: v.Version}
+		if !readJSON(w, r, req) {
+			return
+		}
+		if req.IsStable {
+			for _, o := range s.versions {
+				o.IsStable = false
+			}
+		}
+		id := v.ID
+		v.Version = req.Version
+		v.ID = id
+		writeJSON(w, http.StatusOK, v.Version)
+	case "DELETE":
+		if v.IsStable {
+			writeError(w, http.StatusBadRequest, "VERSION_CANT_REMOVE_STABLE", "You cannot delete the stable version.")
+			return
+		}
+		for i, o := range s.versions {
+			if o == v {
+				s.versions = append(s.versions[:i], s.versions[i+1:]...)
+				break
+			}
+		}
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		methodNotAllowed(w, r)
+	}
+}
+
+func sortedCategories(cats []*readme.Category) []*readme.Category {
+	res := append([]*readme.Category(nil), cats...)
+	sort.SliceStable(res, func(i, j int) bool {
+		return res[i].Order < res[j].Order
+	})
+	return res
+}
+
+// writePage writes a page of items selected by the perPage and page query
+// parameters, with the link and x-total-count headers ReadMe sends.
+func writePage(w http.ResponseWriter, r *http.Request, items []interface{}) {
+	q := r.URL.Query()
+	perPage, err := strconv.Atoi(q.Get("perPage"))
+	if err != nil || perPage <= 0 {
+		perPage = defaultPerPage
+	}
+	if perPage > maxPerPage {
+		perPage = maxPerPage
+	}
+	page, err := strconv.Atoi(q.Get("page"))
+	if err != nil || page <= 0 {
+		page = 1
+	}
+	last := (len(items) + perPage - 1) / perPage
+	if last == 0 {
+		last = 1
+	}
+	pageURL := func(p int) string {
+		u := url.URL{Path: r.URL.Path}
+		v := url.Values{}
+		v.Set("perPage", strconv.Itoa(perPage))
+		v.Set("page", strconv.Itoa(p))
+		u.RawQuery = v.Encode()
+		return u.String()
+	}
+	links := make([]string, 0)
+	if page < last {
+		links = append(links, fmt.Sprintf(`<%s>; rel="next"`, pageURL(page+1)))
+	}
+	if page > 1 {
+		links = append(links, fmt.Sprintf(`<%s>; rel="prev"`, pageURL(page-1)))
+	}
+	links = append(links, fmt.Sprintf(`<%s>; rel="last"`, pageURL(last)))
+	w.Header().Set("link", strings.Join(links, ", "))
+	w.Header().Set("x-total-count", strconv.Itoa(len(items)))
+	start := (page - 1) * perPage
+	if start > len(items) {
+		start = len(items)
+	}
+	end := start + perPage
+	if end > len(items) {
+		end = len(items)
+	}
+	writeJSON(w, http.StatusOK, items[start:end])
+}
+
+func readJSON(w http.ResponseWriter, r *http.Request, v interface{}) bool {
+	err := json.NewDecoder(r.Body).Decode(v)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_JSON", fmt.Sprintf("The request body is not valid JSON: %s", err.Error()))
+		return false
+	}
+	return true
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, code, message string) {
+	writeJSON(w, status, &readme.Error{
+		ErrorCode:  code,
+		Message:    message,
+		Suggestion: "This is a fake ReadMe server for testing.",
+	})
+}
+
+func methodNotAllowed(w http.ResponseWriter, r *http.Request) {
+	writeError(w, http.StatusMethodNotAllowed, "METHOD_NOT_ALLOWED", fmt.Sprintf("%s is not allowed on '%s'.", r.Method, r.URL.Path))
+}
@@ -0,0 +1,352 @@
+// Package readmetest provides an in-memory fake of the ReadMe API for
+// testing clients offline.
+//
+//	fake := readmetest.NewServer("key")
+//	ts := httptest.NewServer(fake)
+//	defer ts.Close()
+//	client := readmetest.NewClient(ts, "key")
+package readmetest
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/cedricshih/readme/api/readme"
+)
+
+// Server is an http.Handler serving projects, categories, docs and
+// versions from memory. Its methods are safe for concurrent use.
+type Server struct {
+	mu       sync.Mutex
+	apiKey   string
+	project  readme.Project
+	versions []*version
+	failures []int
+	// retryAfter is the Retry-After of injected 429s in seconds
+	retryAfter int
+	requests   []string
+	nextID     int
+}
+
+type version struct {
+	readme.Version
+	categories []*readme.Category
+	docs       []*readme.Doc
+}
+
+// NewServer returns a fake project accepting apiKey with a single stable
+// version "1.0".
+func NewServer(apiKey string) *Server {
+	s := &Server{
+		apiKey: apiKey,
+		project: readme.Project{
+			Name:      "Test",
+			SubDomain: "test",
+			BaseUrl:   "https://test.readme.io",
+			Plan:      "free",
+		},
+	}
+	s.versions = append(s.versions, &version{
+		Version: readme.Version{
+			ID:       s.newID(),
+			Version:  "1.0",
+			IsStable: true,
+		},
+	})
+	return s
+}
+
+// NewClient returns a client for the fake served by ts, without throttling
+// and with short retry delays.
+func NewClient(ts *httptest.Server, apiKey string) *readme.Client {
+	c := readme.NewClient(apiKey)
+	c.Client = ts.Client()
+	c.Endpoint = ts.URL + "/"
+	c.Limiter = nil
+	c.Retry.MinBackoff = 0
+	c.Retry.MaxBackoff = 0
+	return c
+}
+
+// Project returns the project served.
+func (s *Server) Project() readme.Project {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.project
+}
+
+// SetProject replaces the project served.
+func (s *Server) SetProject(prj readme.Project) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.project = prj
+}
+
+// Fail makes the next requests fail with the given statuses in order,
+// e.g. to exercise retries.
+func (s *Server) Fail(statuses ...int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failures = append(s.failures, statuses...)
+}
+
+// RetryAfter sets the Retry-After header of injected 429 failures, which is
+// 0 by default.
+func (s *Server) RetryAfter(seconds int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.retryAfter = seconds
+}
+
+// Requests returns "<method> <path>" of every request received so far.
+func (s *Server) Requests() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.requests...)
+}
+
+// AddVersion adds a version with the categories and docs of from, or empty
+// if from is "".
+func (s *Server) AddVersion(ver readme.Version, from string) *readme.Version {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	v := s.addVersion(ver, s.version(from))
+	res := v.Version
+	return &res
+}
+
+// AddCategory adds a category to ver, "" for the stable version. Slug and
+// ID are generated if empty.
+func (s *Server) AddCategory(ver string, cat readme.Category) *readme.Category {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	v := s.version(ver)
+	if v == nil {
+		return nil
+	}
+	res := s.addCategory(v, cat)
+	return &res
+}
+
+// AddDoc adds a doc to the category of ver with slug cat. Slug is
+// generated if empty, Order is ReadMe's default 999 if 0, and ParentDoc is
+// the ID of another doc if set.
+func (s *Server) AddDoc(ver, cat string, doc readme.Doc) *readme.Doc {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	v := s.version(ver)
+	if v == nil {
+		return nil
+	}
+	c := v.category(cat)
+	if c == nil {
+		return nil
+	}
+	doc.Category = c.ID
+	if doc.Order == 0 {
+		doc.Order = 999
+	}
+	res := s.addDoc(v, doc)
+	return &res
+}
+
+// Doc returns a copy of the doc of ver with slug, or nil.
+func (s *Server) Doc(ver, slug string) *readme.Doc {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	v := s.version(ver)
+	if v == nil {
+		return nil
+	}
+	d := v.doc(slug)
+	if d == nil {
+		return nil
+	}
+	res := *d
+	return &res
+}
+
+// Category returns a copy of the category of ver with slug, or nil.
+func (s *Server) Category(ver, slug string) *readme.Category {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	v := s.version(ver)
+	if v == nil {
+		return nil
+	}
+	c := v.category(slug)
+	if c == nil {
+		return nil
+	}
+	res := *c
+	return &res
+}
+
+func (s *Server) newID() string {
+	s.nextID++
+	return fmt.Sprintf("%024x", s.nextID)
+}
+
+// version returns the version named ver, or the stable one if ver is "".
+func (s *Server) version(ver string) *version {
+	ver = strings.TrimPrefix(ver, "v")
+	for _, v := range s.versions {
+		if ver == "" && v.IsStable || ver != "" && v.Version.Version == ver {
+			return v
+		}
+	}
+	return nil
+}
+
+func (s *Server) addVersion(ver readme.Version, from *version) *version {
+	ver.ID = s.newID()
+	v := &version{Version: ver}
+	if from != nil {
+		ids := make(map[string]string)
+		for _, c := range from.categories {
+			cat := *c
+			cat.ID = s.newID()
+			ids[c.ID] = cat.ID
+			v.categories = append(v.categories, &cat)
+		}
+		for _, d := range from.docs {
+			doc := *d
+			doc.ID = s.newID()
+			doc.Category = ids[d.Category]
+			ids[d.ID] = doc.ID
+			v.docs = append(v.docs, &doc)
+		}
+		for _, d := range v.docs {
+			d.ParentDoc = ids[d.ParentDoc]
+		}
+	}
+	if ver.IsStable {
+		for _, o := range s.versions {
+			o.IsStable = false
+		}
+	}
+	s.versions = append(s.versions, v)
+	return v
+}
+
+func (s *Server) addCategory(v *version, cat readme.Category) readme.Category {
+	if cat.ID == "" {
+		cat.ID = s.newID()
+	}
+	if cat.Slug == "" {
+		cat.Slug = uniqueSlug(cat.Title, func(slug string) bool {
+			return v.category(slug) != nil
+		})
+	}
+	if cat.Type == "" {
+		cat.Type = readme.CategoryTypeGuide
+	}
+	if cat.Order == 0 {
+		cat.Order = len(v.categories)
+	}
+	res := cat
+	v.categories = append(v.categories, &cat)
+	return res
+}
+
+func (s *Server) addDoc(v *version, doc readme.Doc) readme.Doc {
+	doc.ID = s.newID()
+	doc.Children = nil
+	if doc.Type == "" {
+		doc.Type = readme.DocTypeBasic
+	}
+	if doc.Slug == "" {
+		doc.Slug = uniqueSlug(doc.Title, func(slug string) bool {
+			return v.doc(slug) != nil
+		})
+	}
+	res := doc
+	v.docs = append(v.docs, &doc)
+	return res
+}
+
+func (v *version) category(slug string) *readme.Category {
+	for _, c := range v.categories {
+		if c.Slug == slug {
+			return c
+		}
+	}
+	return nil
+}
+
+func (v *version) doc(slug string) *readme.Doc {
+	for _, d := range v.docs {
+		if d.Slug == slug {
+			return d
+		}
+	}
+	return nil
+}
+
+func uniqueSlug(title string, exists func(string) bool) string {
+	base := readme.Slugify(title)
+	slug := base
+	for i := 1; exists(slug); i++ {
+		slug = fmt.Sprintf("%s-%d", base, i)
+	}
+	return slug
+}
+
+func (s *Server) authorized(r *http.Request) bool {
+	want := "Basic " + base64.RawStdEncoding.EncodeToString([]byte(s.apiKey+":"))
+	wantPadded := "Basic " + base64.StdEncoding.EncodeToString([]byte(s.apiKey+":"))
+	got := r.Header.Get("Authorization")
+	return got == want || got == wantPadded
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests = append(s.requests, r.Method+" "+r.URL.Path)
+	w.Header().Set("x-request-id", s.newID())
+	if len(s.failures) > 0 {
+		status := s.failures[0]
+		s.failures = s.failures[1:]
+		if status == http.StatusTooManyRequests {
+			w.Header().Set("Retry-After", strconv.Itoa(s.retryAfter))
+		}
+		writeError(w, status, "INJECTED_FAILURE", "Injected failure")
+		return
+	}
+	if !s.authorized(r) {
+		writeError(w, http.StatusUnauthorized, "APIKEY_NOTFOUND", "We couldn't find your API key.")
+		return
+	}
+	ver := r.Header.Get("x-readme-version")
+	v := s.version(ver)
+	if v == nil {
+		writeError(w, http.StatusNotFound, "VERSION_NOTFOUND", fmt.Sprintf("The version '%s' couldn't be found.", ver))
+		return
+	}
+	path := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	switch {
+	case len(path) == 1 && path[0] == "":
+		s.serveProject(w, r)
+	case path[0] == "categories":
+		s.serveCategories(w, r, v, path[1:])
+	case path[0] == "docs":
+		s.serveDocs(w, r, v, path[1:])
+	case path[0] == "version":
+		s.serveVersions(w, r, path[1:])
+	default:
+		writeError(w, http.StatusNotFound, "ENDPOINT_NOTFOUND", fmt.Sprintf("The endpoint '%s' couldn't be found.", r.URL.Path))
+	}
+}
+
+func (s *Server) serveProject(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		methodNotAllowed(w, r)
+		return
+	}
+	writeJSON(w, http.StatusOK, s.project)
+}
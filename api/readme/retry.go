@@ -0,0 +1,175 @@
+package readme
+
+import (
+	"context"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// RetryPolicy controls how failed requests are retried.
+type RetryPolicy struct {
+	// MaxRetries is the number of retries after the first attempt.
+	MaxRetries int
+	// MinBackoff is the delay before the first retry, doubled on each
+	// following retry up to MaxBackoff.
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+	// NonIdempotent also retries POST requests after a server or network
+	// error, which may create duplicates.
+	NonIdempotent bool
+}
+
+func DefaultRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		MaxRetries: 5,
+		MinBackoff: 500 * time.Millisecond,
+		MaxBackoff: 30 * time.Second,
+	}
+}
+
+// networkError is a failure to send a request or to read its response,
+// which unlike a request that cannot be built may succeed when retried.
+type networkError struct {
+	err error
+}
+
+func (e *networkError) Error() string {
+	return e.err.Error()
+}
+
+// retryable reports whether a request may be retried after failing with
+// status, which is 0 after a network error.
+func (p *RetryPolicy) retryable(method string, status int) bool {
+	if status == http.StatusTooManyRequests {
+		// rejected before being processed, safe for any method
+		return true
+	}
+	if !p.NonIdempotent && !isIdempotent(method) {
+		return false
+	}
+	switch status {
+	case 0, http.StatusInternalServerError, http.StatusBadGateway,
+		http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// backoff returns the delay before the given retry, starting from 0, with
+// jitter in [d/2, d) to spread out concurrent clients.
+func (p *RetryPolicy) backoff(retry int) time.Duration {
+	d := p.MinBackoff
+	for i := 0; i < retry && d < p.MaxBackoff; i++ {
+		d *= 2
+	}
+	if p.MaxBackoff > 0 && d > p.MaxBackoff {
+		d = p.MaxBackoff
+	}
+	if d <= 0 {
+		return 0
+	}
+	return d/2 + time.Duration(rand.Int63n(int64(d/2)+1))
+}
+
+func isIdempotent(method string) bool {
+	switch method {
+	case "GET", "HEAD", "OPTIONS", "PUT", "DELETE":
+		return true
+	}
+	return false
+}
+
+// retryAfter returns the delay requested by the Retry-After or
+// x-ratelimit-reset headers, or 0 if there is none.
+func retryAfter(h http.Header, now time.Time) time.Duration {
+	if v := h.Get("Retry-After"); v != "" {
+		if secs, err := strconv.Atoi(v); err == nil {
+			return time.Duration(secs) * time.Second
+		}
+		if t, err := http.ParseTime(v); err == nil {
+			return t.Sub(now)
+		}
+	}
+	return rateLimitReset(h, now)
+}
+
+// rateLimitReset parses x-ratelimit-reset, which is either the number of
+// seconds until the window resets or a Unix timestamp.
+func rateLimitReset(h http.Header, now time.Time) time.Duration {
+	v, err := strconv.ParseInt(h.Get("x-ratelimit-reset"), 10, 64)
+	if err != nil || v <= 0 {
+		return 0
+	}
+	if v > now.Unix()-86400 {
+		return time.Unix(v, 0).Sub(now)
+	}
+	return time.Duration(v) * time.Second
+}
+
+// RateLimiter spaces out requests to at most a fixed rate, and pauses all
+// requests when the server reports the quota is used up.
+type RateLimiter struct {
+	interval time.Duration
+	mu       sync.Mutex
+	next     time.Time
+}
+
+// NewRateLimiter returns a limiter allowing perSecond requests per second,
+// or only pausing on server quota if perSecond is not positive.
+func NewRateLimiter(perSecond float64) *RateLimiter {
+	l := &RateLimiter{}
+	if perSecond > 0 {
+		l.interval = time.Duration(float64(time.Second) / perSecond)
+	}
+	return l
+}
+
+// Wait blocks until the next request may be sent or ctx is done.
+func (l *RateLimiter) Wait(ctx context.Context) error {
+	l.mu.Lock()
+	now := time.Now()
+	at := l.next
+	if at.Before(now) {
+		at = now
+	}
+	l.next = at.Add(l.interval)
+	l.mu.Unlock()
+	return sleep(ctx, at.Sub(now))
+}
+
+// pause holds back all requests for d.
+func (l *RateLimiter) pause(d time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	at := time.Now().Add(d)
+	if at.After(l.next) {
+		l.next = at
+	}
+}
+
+func (l *RateLimiter) observe(h http.Header) {
+	remaining, err := strconv.Atoi(h.Get("x-ratelimit-remaining"))
+	if err != nil || remaining > 0 {
+		return
+	}
+	if d := rateLimitReset(h, time.Now()); d > 0 {
+		l.pause(d)
+	}
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
@@ -0,0 +1,50 @@
+package readme
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestRetryAfterHeaders(t *testing.T) {
+	now := time.Date(2022, 3, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name   string
+		header map[string]string
+		want   time.Duration
+	}{
+		{"none", nil, 0},
+		{"seconds", map[string]string{"Retry-After": "3"}, 3 * time.Second},
+		{"date", map[string]string{"Retry-After": now.Add(time.Minute).Format(http.TimeFormat)}, time.Minute},
+		{"reset seconds", map[string]string{"x-ratelimit-reset": "20"}, 20 * time.Second},
+		{"reset timestamp", map[string]string{"x-ratelimit-reset": "1646136030"}, 30 * time.Second},
+		{"retry-after first", map[string]string{"Retry-After": "1", "x-ratelimit-reset": "20"}, time.Second},
+		{"invalid", map[string]string{"Retry-After": "soon"}, 0},
+	}
+	for _, tt := range tests {
+		h := make(http.Header)
+		for k, v := range tt.header {
+			h.Set(k, v)
+		}
+		if got := retryAfter(h, now); got != tt.want {
+			t.Errorf("%s: got %s, want %s", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestRateLimiterObserve(t *testing.T) {
+	l := NewRateLimiter(0)
+	h := make(http.Header)
+	h.Set("x-ratelimit-remaining", "0")
+	h.Set("x-ratelimit-reset", "1")
+	l.observe(h)
+	start := time.Now()
+	err := l.Wait(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d := time.Since(start); d < 500*time.Millisecond {
+		t.Errorf("waited %s after the quota is used up, want about 1s", d)
+	}
+}
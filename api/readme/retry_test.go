@@ -0,0 +1,143 @@
+package readme_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/cedricshih/readme/api/readme"
+)
+
+func TestRetryAfter(t *testing.T) {
+	srv, c := newTestClient(t)
+	srv.RetryAfter(1)
+	srv.Fail(429)
+	start := time.Now()
+	_, err := c.Categories()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d := time.Since(start); d < time.Second {
+		t.Errorf("retried after %s, want Retry-After of 1s", d)
+	}
+	if n := countRequests(srv, "GET /categories"); n != 2 {
+		t.Errorf("got %d requests, want 2", n)
+	}
+}
+
+func TestRetryServerErrors(t *testing.T) {
+	tests := []struct {
+		name          string
+		get           bool
+		nonIdempotent bool
+		failures      []int
+		wantErr       bool
+		wantRequests  int
+	}{
+		{"transient", true, false, []int{503, 500, 502}, false, 4},
+		{"client error", true, false, []int{400}, true, 1},
+		{"post", false, false, []int{503}, true, 1},
+		{"post when allowed", false, true, []int{503}, false, 2},
+		{"rate limited post", false, false, []int{429}, false, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, c := newTestClient(t)
+			cat := srv.AddCategory("", readme.Category{Title: "Guides"})
+			c.Retry.NonIdempotent = tt.nonIdempotent
+			srv.Fail(tt.failures...)
+			var err error
+			req := "POST /docs"
+			if tt.get {
+				req = "GET /categories/guides"
+				_, err = c.Category("guides")
+			} else {
+				_, err = c.CreateDoc(cat.ID, &readme.Doc{Title: "Intro"})
+			}
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("got %v, want error %v", err, tt.wantErr)
+			}
+			if err != nil && !readme.IsStatus(err, tt.failures[0]) {
+				t.Errorf("got %v, want status %d", err, tt.failures[0])
+			}
+			if n := countRequests(srv, req); n != tt.wantRequests {
+				t.Errorf("got %d requests, want %d", n, tt.wantRequests)
+			}
+		})
+	}
+}
+
+func TestRetryBounded(t *testing.T) {
+	srv, c := newTestClient(t)
+	c.Retry.MaxRetries = 2
+	srv.Fail(503, 503, 503, 503, 503)
+	_, err := c.Doc("intro")
+	if !readme.IsStatus(err, 503) {
+		t.Fatalf("got %v, want 503", err)
+	}
+	if n := countRequests(srv, "GET /docs/intro"); n != 3 {
+		t.Errorf("got %d requests, want 3", n)
+	}
+}
+
+type failingTransport struct {
+	requests int
+}
+
+func (t *failingTransport) RoundTrip(*http.Request) (*http.Response, error) {
+	t.requests++
+	return nil, errors.New("connection refused")
+}
+
+func TestRetryNetworkErrors(t *testing.T) {
+	_, c := newTestClient(t)
+	tr := &failingTransport{}
+	c.Client = &http.Client{Transport: tr}
+	c.Retry.MaxRetries = 2
+	_, err := c.Doc("intro")
+	if err == nil {
+		t.Fatal("got no error")
+	}
+	if tr.requests != 3 {
+		t.Errorf("got %d requests, want 3", tr.requests)
+	}
+
+	// a request that cannot be built fails the same way every time
+	c.Endpoint = "http://[::1/"
+	c.Retry.MinBackoff = time.Hour
+	c.Retry.MaxBackoff = time.Hour
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	_, err = c.DocContext(ctx, "intro")
+	if err == nil || errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("got %v, want an error without retries", err)
+	}
+}
+
+func TestRetryCancel(t *testing.T) {
+	srv, c := newTestClient(t)
+	srv.RetryAfter(10)
+	srv.Fail(429)
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err := c.DocContext(ctx, "intro")
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("got %v, want deadline exceeded", err)
+	}
+	if d := time.Since(start); d > 5*time.Second {
+		t.Errorf("cancelled after %s", d)
+	}
+	if n := countRequests(srv, "GET /docs/intro"); n != 1 {
+		t.Errorf("got %d requests, want 1", n)
+	}
+
+	ctx, cancel = context.WithCancel(context.Background())
+	cancel()
+	_, err = c.DocContext(ctx, "intro")
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("got %v, want canceled", err)
+	}
+}
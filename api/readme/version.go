@@ -0,0 +1,11 @@
+package readme
+
+type Version struct {
+	ID           string `json:"_id"`
+	Version      string `json:"version"`
+	Codename     string `json:"codename"`
+	IsStable     bool   `json:"is_stable"`
+	IsBeta       bool   `json:"is_beta"`
+	IsHidden     bool   `json:"is_hidden"`
+	IsDeprecated bool   `json:"is_deprecated"`
+}
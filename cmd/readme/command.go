@@ -1,9 +1,59 @@
 package main
 
-import "io"
+import (
+	"flag"
+	"io"
+	"sort"
+)
 
 type Command interface {
 	Usage(w io.Writer, progname, cmdname string)
 	MinArguments() int
 	Run(args []string) error
 }
+
+// FlagCommand is implemented by commands accepting their own flags after
+// the command name.
+type FlagCommand interface {
+	Command
+	Flags(fs *flag.FlagSet)
+}
+
+// ResultCommand is implemented by commands with a result that -o can emit
+// as a JSON or YAML document, for the given arguments.
+type ResultCommand interface {
+	Command
+	HasResult(args []string) bool
+}
+
+type Subcommand struct {
+	Name    string
+	Aliases []string
+	Summary string
+	Command Command
+}
+
+type Registry []*Subcommand
+
+func (r Registry) Lookup(name string) *Subcommand {
+	for _, sub := range r {
+		if sub.Name == name {
+			return sub
+		}
+		for _, alias := range sub.Aliases {
+			if alias == name {
+				return sub
+			}
+		}
+	}
+	return nil
+}
+
+func (r Registry) Sorted() Registry {
+	res := make(Registry, len(r))
+	copy(res, r)
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].Name < res[j].Name
+	})
+	return res
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+type DeleteDocument struct {
+	*RemoteCommand
+}
+
+func (c *DeleteDocument) Usage(w io.Writer, progname, cmdname string) {
+	fmt.Fprintf(w, "%s %s <slug-like>\n\n", progname, cmdname)
+	fmt.Fprintf(w, "Examples:\n\n")
+	fmt.Fprintf(w, "%s %s quick-start\n", progname, cmdname)
+	fmt.Fprintf(w, "%s %s guides/quick-start.md\n", progname, cmdname)
+}
+
+func (c *DeleteDocument) MinArguments() int {
+	return 1
+}
+
+func (c *DeleteDocument) HasResult(args []string) bool {
+	return true
+}
+
+func (c *DeleteDocument) Run(args []string) error {
+	err := c.run(args)
+	if err != nil {
+		return err
+	}
+	return c.emitActions()
+}
+
+func (c *DeleteDocument) run(args []string) error {
+	slug := args[0]
+	slug = filepath.Base(slug)
+	slug = strings.TrimSuffix(slug, filepath.Ext(slug))
+	meta, err := c.metadata()
+	if err != nil {
+		return err
+	}
+	cat, _, exist := meta.Doc(slug)
+	cont, err := c.confirmDestructive("Are you sure to delete doc '%s' from remote?", slug)
+	if err != nil {
+		return err
+	}
+	if !cont {
+		c.printf("Doc '%s' is not deleted", slug)
+		c.record(meta, cat, slug, actionSkipped)
+		return nil
+	}
+	err = c.deleteDoc(meta, slug)
+	if err != nil {
+		return err
+	}
+	c.record(meta, cat, slug, actionDeleted)
+	if exist == nil {
+		return nil
+	}
+	path := c.docFilePath(cat, slug)
+	c.printf("Removing doc: %s", path)
+	err = c.remove(path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return c.writeMetadata(meta)
+}
@@ -0,0 +1,236 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/cedricshih/readme/api/readme"
+	"github.com/cedricshih/readme/repository"
+)
+
+type docFrontMatter struct {
+	Title    string `yaml:"title"`
+	Excerpt  string `yaml:"excerpt,omitempty"`
+	Hidden   bool   `yaml:"hidden"`
+	Category string `yaml:"category,omitempty"`
+	Parent   string `yaml:"parent,omitempty"`
+	// Order is nil if the file leaves the order as it is.
+	Order *int   `yaml:"order,omitempty"`
+	Type  string `yaml:"type,omitempty"`
+}
+
+// newDocFrontMatter returns the front matter of doc in category cat, nested
+// under the doc with slug parent if it is not empty.
+func newDocFrontMatter(cat, parent string, doc *readme.Doc) *docFrontMatter {
+	order := doc.Order
+	return &docFrontMatter{
+		Title:    doc.Title,
+		Excerpt:  doc.Excerpt,
+		Hidden:   doc.Hidden,
+		Category: cat,
+		Parent:   parent,
+		Order:    &order,
+		Type:     doc.Type,
+	}
+}
+
+func (fm *docFrontMatter) apply(doc *readme.Doc) {
+	doc.Title = fm.Title
+	doc.Excerpt = fm.Excerpt
+	doc.Hidden = fm.Hidden
+	if fm.Order != nil {
+		doc.Order = *fm.Order
+	}
+	if fm.Type != "" {
+		doc.Type = fm.Type
+	}
+}
+
+// checkPlacement returns an error if the category or parent given in the
+// front matter of the file at path disagree with the folder it is in.
+func (fm *docFrontMatter) checkPlacement(path, cat, parent string) error {
+	if fm.Category != "" && fm.Category != cat {
+		return fmt.Errorf("%s: category '%s' in front matter does not match the folder of category '%s', please move the file or fix its front matter", path, fm.Category, cat)
+	}
+	if fm.Parent != "" && fm.Parent != parent {
+		if parent == "" {
+			return fmt.Errorf("%s: parent '%s' in front matter does not match the folder, which is not nested under a doc, please move the file or fix its front matter", path, fm.Parent)
+		}
+		return fmt.Errorf("%s: parent '%s' in front matter does not match the folder of parent '%s', please move the file or fix its front matter", path, fm.Parent, parent)
+	}
+	return nil
+}
+
+// readDocFile reads a Markdown doc, returning nil front matter if the file
+// does not start with one.
+func readDocFile(path string) (*docFrontMatter, string, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, "", err
+	}
+	if !bytes.HasPrefix(data, []byte(frontMatterDelimiter)) && !bytes.HasPrefix(data, []byte("---\r\n")) {
+		return nil, string(data), nil
+	}
+	fm := &docFrontMatter{}
+	body, err := parseFrontMatter(data, fm)
+	if err != nil {
+		return nil, "", err
+	}
+	return fm, body, nil
+}
+
+// writeDocFile writes a Markdown doc, with front matter if fm is not nil.
+func (c *RemoteCommand) writeDocFile(path string, fm *docFrontMatter, body string) error {
+	if fm != nil {
+		return c.writeFrontMatter(path, fm, body)
+	}
+	return c.writeFile(path, []byte(body))
+}
+
+// localDoc reads a doc from wherever it is in the category folder, taking
+// its parent from the folder it is nested in.
+func (c *RemoteCommand) localDoc(meta *repository.Metadata, cat, slug string, docMeta *repository.Doc) (*readme.Doc, error) {
+	path, parents, err := c.repo().FindDoc(cat, slug)
+	if err != nil {
+		return nil, err
+	}
+	doc := &readme.Doc{
+		ID:      docMeta.ID,
+		Slug:    slug,
+		Title:   docMeta.Title,
+		Excerpt: docMeta.Excerpt,
+		Hidden:  docMeta.Hidden,
+		Type:    docMeta.Type,
+		Order:   docMeta.Order,
+	}
+	parent := ""
+	if len(parents) > 0 {
+		parent = parents[len(parents)-1]
+		parentMeta := meta.Categories[cat].Docs[parent]
+		if parentMeta == nil || parentMeta.ID == "" {
+			return nil, fmt.Errorf("parent doc '%s' of '%s' is not on ReadMe yet, please push it first", parent, slug)
+		}
+		doc.ParentDoc = parentMeta.ID
+	}
+	if !c.frontMatter {
+		body, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		doc.Body = string(body)
+		return doc, nil
+	}
+	fm, body, err := readDocFile(path)
+	if err != nil {
+		return nil, err
+	}
+	doc.Body = body
+	if fm != nil {
+		err = fm.checkPlacement(path, cat, parent)
+		if err != nil {
+			return nil, err
+		}
+		fm.apply(doc)
+	}
+	return doc, nil
+}
+
+// writeLocalDoc writes doc under the folders of its parents in meta, moving
+// it if it was elsewhere in the category folder.
+func (c *RemoteCommand) writeLocalDoc(meta *repository.Metadata, cat string, doc *readme.Doc) error {
+	parents := meta.Parents(cat, doc.Slug)
+	path := c.repo().DocPath(cat, parents, doc.Slug)
+	old, _, err := c.repo().FindDoc(cat, doc.Slug)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	c.printf("Writing doc: %s", path)
+	if c.frontMatter {
+		parent := ""
+		if len(parents) > 0 {
+			parent = parents[len(parents)-1]
+		}
+		err = c.writeDocFile(path, newDocFrontMatter(cat, parent, doc), doc.Body)
+	} else {
+		err = c.writeDocFile(path, nil, doc.Body)
+	}
+	if err != nil || old == "" || old == path {
+		return err
+	}
+	c.printf("Removing doc moved to '%s': %s", path, old)
+	err = c.remove(old)
+	if err != nil {
+		return err
+	}
+	c.removeEmptyDirs(filepath.Dir(old), c.categoryPath(cat))
+	return nil
+}
+
+// checkPlacement returns an error if the front matter of a doc disagrees
+// with the folder it is in.
+func (c *RemoteCommand) checkPlacement(cat, slug string) error {
+	if !c.frontMatter {
+		return nil
+	}
+	path, parents, err := c.repo().FindDoc(cat, slug)
+	if err != nil {
+		return err
+	}
+	fm, _, err := readDocFile(path)
+	if err != nil || fm == nil {
+		return err
+	}
+	parent := ""
+	if len(parents) > 0 {
+		parent = parents[len(parents)-1]
+	}
+	return fm.checkPlacement(path, cat, parent)
+}
+
+// updatePlacement rewrites the category and parent in the front matter of
+// a doc moved to another folder to match it.
+func (c *RemoteCommand) updatePlacement(cat, slug string) error {
+	if !c.frontMatter {
+		return nil
+	}
+	path, parents, err := c.repo().FindDoc(cat, slug)
+	if os.IsNotExist(err) {
+		// not written yet, or only planned to be moved in a dry run
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	fm, body, err := readDocFile(path)
+	if err != nil || fm == nil {
+		return err
+	}
+	parent := ""
+	if len(parents) > 0 {
+		parent = parents[len(parents)-1]
+	}
+	if fm.Category == cat && fm.Parent == parent {
+		return nil
+	}
+	fm.Category = cat
+	fm.Parent = parent
+	return c.writeDocFile(path, fm, body)
+}
+
+// removeEmptyDirs removes dir and its parents up to but excluding root as
+// long as they are empty.
+func (c *RemoteCommand) removeEmptyDirs(dir, root string) {
+	if c.dryRun {
+		return
+	}
+	for dir != root && strings.HasPrefix(dir, root) {
+		if os.Remove(dir) != nil {
+			return
+		}
+		dir = filepath.Dir(dir)
+	}
+}
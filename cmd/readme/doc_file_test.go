@@ -0,0 +1,108 @@
+package main
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/cedricshih/readme/api/readme"
+)
+
+func TestDocFrontMatter(t *testing.T) {
+	srv, c := newTestCommand(t)
+	srv.AddCategory("", readme.Category{Title: "Guides"})
+	intro := srv.AddDoc("", "guides", readme.Doc{Slug: "intro", Title: "Intro", Body: "hello\n", Order: 3})
+	srv.AddDoc("", "guides", readme.Doc{Slug: "setup", Title: "Setup", Body: "install\n", ParentDoc: intro.ID})
+	err := (&Synchronize{c}).Run(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = (&Migrate{c}).Run(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	setupPath := filepath.Join(c.docRoot, "guides", "intro", "setup.md")
+	data, err := ioutil.ReadFile(setupPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range []string{"category: guides\n", "parent: intro\n", "order: 999\n"} {
+		if !strings.Contains(string(data), key) {
+			t.Errorf("front matter %q has no %q", data, key)
+		}
+	}
+
+	meta, err := c.metadata()
+	if err != nil {
+		t.Fatal(err)
+	}
+	introPath := filepath.Join(c.docRoot, "guides", "intro.md")
+	tests := []struct {
+		name      string
+		path      string
+		slug      string
+		data      string
+		wantOrder int
+		wantErr   bool
+	}{
+		{"no order", introPath, "intro", "---\ntitle: Intro\n---\nhello\n", 3, false},
+		{"order", introPath, "intro", "---\ntitle: Intro\norder: 0\n---\nhello\n", 0, false},
+		{"category", introPath, "intro", "---\ntitle: Intro\ncategory: guides\n---\nhello\n", 3, false},
+		{"wrong category", introPath, "intro", "---\ntitle: Intro\ncategory: api\n---\nhello\n", 0, true},
+		{"wrong parent", setupPath, "setup", "---\ntitle: Setup\nparent: other\n---\ninstall\n", 0, true},
+		{"parent at top level", introPath, "intro", "---\ntitle: Intro\nparent: setup\n---\nhello\n", 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ioutil.WriteFile(tt.path, []byte(tt.data), 0644)
+			if err != nil {
+				t.Fatal(err)
+			}
+			doc, err := c.localDoc(meta, "guides", tt.slug, meta.Categories["guides"].Docs[tt.slug])
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("got %v, want error %v", err, tt.wantErr)
+			}
+			if err == nil && doc.Order != tt.wantOrder {
+				t.Errorf("got order %d, want %d", doc.Order, tt.wantOrder)
+			}
+		})
+	}
+}
+
+func TestDocFrontMatterMoved(t *testing.T) {
+	srv, c := newTestCommand(t)
+	srv.AddCategory("", readme.Category{Title: "Guides"})
+	srv.AddCategory("", readme.Category{Title: "Reference"})
+	intro := srv.AddDoc("", "guides", readme.Doc{Slug: "intro", Title: "Intro", Body: "hello\n"})
+	srv.AddDoc("", "guides", readme.Doc{Slug: "setup", Title: "Setup", Body: "install\n", ParentDoc: intro.ID})
+	err := (&Synchronize{c}).Run(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = (&Migrate{c}).Run(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = (&RenameDocument{c}).Run([]string{"intro", "introduction"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = (&MoveDocument{c}).Run([]string{"introduction", "reference"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := ioutil.ReadFile(filepath.Join(c.docRoot, "reference", "introduction", "setup.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range []string{"category: reference\n", "parent: introduction\n"} {
+		if !strings.Contains(string(data), key) {
+			t.Errorf("front matter %q has no %q", data, key)
+		}
+	}
+	want := map[string]string{"introduction": statusUnchanged, "setup": statusUnchanged}
+	if got := docStates(t, c); !equalStates(got, want) {
+		t.Errorf("after move got %v, want %v", got, want)
+	}
+}
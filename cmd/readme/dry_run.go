@@ -0,0 +1,109 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/cedricshih/readme/repository"
+)
+
+// localOp is a local file change not made in dry run mode.
+type localOp struct {
+	op   string
+	path string
+}
+
+type localPlan struct {
+	mu  sync.Mutex
+	ops []localOp
+}
+
+func (p *localPlan) add(op, path string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, o := range p.ops {
+		if o.op == op && o.path == path {
+			return
+		}
+	}
+	p.ops = append(p.ops, localOp{op, path})
+}
+
+func (c *RemoteCommand) writeFile(path string, data []byte) error {
+	if c.dryRun {
+		c.plan.add("write", path)
+		return nil
+	}
+	return repository.WriteFile(path, data)
+}
+
+func (c *RemoteCommand) rename(old, new string) error {
+	if c.dryRun {
+		_, err := os.Stat(old)
+		if err != nil {
+			return err
+		}
+		c.plan.add("rename", fmt.Sprintf("%s => %s", old, new))
+		return nil
+	}
+	return os.Rename(old, new)
+}
+
+func (c *RemoteCommand) remove(path string) error {
+	if c.dryRun {
+		_, err := os.Stat(path)
+		if err != nil {
+			return err
+		}
+		c.plan.add("remove", path)
+		return nil
+	}
+	return os.Remove(path)
+}
+
+func (c *RemoteCommand) mkdirAll(dir string) error {
+	if c.dryRun {
+		if _, err := os.Stat(dir); os.IsNotExist(err) {
+			c.plan.add("mkdir", dir)
+		}
+		return nil
+	}
+	return os.MkdirAll(dir, os.ModePerm)
+}
+
+// printPlan prints what a dry run would have changed on ReadMe and locally.
+func (c *RemoteCommand) printPlan() {
+	if !c.dryRun {
+		return
+	}
+	creates, updates, deletes := 0, 0, 0
+	c.printf("\nDry run, nothing is changed. Planned changes:")
+	for _, req := range c.client.Planned() {
+		op := req.Method
+		switch req.Method {
+		case "POST":
+			op = "create"
+			creates++
+		case "PUT":
+			op = "update"
+			updates++
+		case "DELETE":
+			op = "delete"
+			deletes++
+		}
+		c.printf("  %-7s %s %s", op, req.Method, req.URL)
+		if req.Body != nil {
+			c.printf("          %s", req.Body)
+		}
+	}
+	c.plan.mu.Lock()
+	defer c.plan.mu.Unlock()
+	local := make(map[string]int)
+	for _, op := range c.plan.ops {
+		c.printf("  %-7s %s", op.op, op.path)
+		local[op.op]++
+	}
+	c.printf("%d creates, %d updates, %d deletes on ReadMe", creates, updates, deletes)
+	c.printf("%d writes, %d renames, %d removes, %d new folders locally", local["write"], local["rename"], local["remove"], local["mkdir"])
+}
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestDryRunCreateCategory(t *testing.T) {
+	srv, c := newTestCommand(t)
+	c.dryRun = true
+	c.client.DryRun = true
+	err := (&ManageCategory{c}).Run([]string{"create", "Getting Started"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.printPlan()
+	out := c.output.(*bytes.Buffer).String()
+	for _, want := range []string{
+		"Category 'getting-started' is created: dry-run-1\n",
+		"1 creates, 0 updates, 0 deletes on ReadMe\n",
+		"1 writes, 0 renames, 0 removes, 1 new folders locally\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output %q has no %q", out, want)
+		}
+	}
+	if cat := srv.Category("", "getting-started"); cat != nil {
+		t.Errorf("got category %+v on ReadMe after a dry run", cat)
+	}
+}
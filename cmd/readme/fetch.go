@@ -0,0 +1,89 @@
+package main
+
+import (
+	"context"
+	"sync"
+
+	"github.com/cedricshih/readme/api/readme"
+)
+
+// parallel calls fn for every index in [0, n) on up to c.jobs goroutines.
+// The context passed to fn is cancelled on the first error, which is
+// returned.
+func (c *RemoteCommand) parallel(n int, fn func(ctx context.Context, i int) error) error {
+	jobs := c.jobs
+	if jobs < 1 {
+		jobs = 1
+	}
+	if jobs > n {
+		jobs = n
+	}
+	ctx, cancel := context.WithCancel(c.ctx)
+	defer cancel()
+	indexes := make(chan int)
+	var once sync.Once
+	var firstErr error
+	var wg sync.WaitGroup
+	for w := 0; w < jobs; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				err := fn(ctx, i)
+				if err != nil {
+					once.Do(func() {
+						firstErr = err
+						cancel()
+					})
+				}
+			}
+		}()
+	}
+	for i := 0; i < n && ctx.Err() == nil; i++ {
+		select {
+		case indexes <- i:
+		case <-ctx.Done():
+		}
+	}
+	close(indexes)
+	wg.Wait()
+	if firstErr != nil {
+		return firstErr
+	}
+	return c.ctx.Err()
+}
+
+// fetchDocs fetches the full docs of every category concurrently, in the
+// order of cats and of the docs in each category.
+func (c *RemoteCommand) fetchDocs(cats []*readme.Category) ([][]*readme.Doc, error) {
+	c.logf(readme.LevelDebug, "Fetching docs of %d categories with %d jobs", len(cats), c.jobs)
+	lists := make([][]*readme.Doc, len(cats))
+	err := c.parallel(len(cats), func(ctx context.Context, i int) error {
+		docs, err := c.client.DocsContext(ctx, cats[i].Slug)
+		lists[i] = docs
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	type ref struct{ cat, doc int }
+	refs := make([]ref, 0)
+	for i, docs := range lists {
+		for j := range docs {
+			refs = append(refs, ref{i, j})
+		}
+	}
+	err = c.parallel(len(refs), func(ctx context.Context, i int) error {
+		r := refs[i]
+		doc, err := c.client.DocContext(ctx, lists[r.cat][r.doc].Slug)
+		if err != nil {
+			return err
+		}
+		lists[r.cat][r.doc] = doc
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return lists, nil
+}
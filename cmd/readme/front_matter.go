@@ -0,0 +1,75 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	frontMatterDelimiter = "---\n"
+)
+
+// parseFrontMatter decodes the YAML front matter of data into meta and
+// returns the remaining body. Data without front matter is returned as the
+// body as is.
+func parseFrontMatter(data []byte, meta interface{}) (string, error) {
+	data = bytes.ReplaceAll(data, []byte("\r\n"), []byte("\n"))
+	if !bytes.HasPrefix(data, []byte(frontMatterDelimiter)) {
+		return string(data), nil
+	}
+	rest := data[len(frontMatterDelimiter):]
+	end := bytes.Index(rest, []byte("\n"+frontMatterDelimiter))
+	head := []byte{}
+	if bytes.HasPrefix(rest, []byte(frontMatterDelimiter)) {
+		rest = rest[len(frontMatterDelimiter):]
+	} else if end >= 0 {
+		head = rest[:end+1]
+		rest = rest[end+1+len(frontMatterDelimiter):]
+	} else if bytes.HasSuffix(rest, []byte("\n---")) {
+		head = rest[:len(rest)-len("---")]
+		rest = []byte{}
+	} else {
+		return "", fmt.Errorf("unterminated front matter")
+	}
+	err := yaml.Unmarshal(head, meta)
+	if err != nil {
+		return "", err
+	}
+	return string(rest), nil
+}
+
+func formatFrontMatter(meta interface{}, body string) ([]byte, error) {
+	head, err := yaml.Marshal(meta)
+	if err != nil {
+		return nil, err
+	}
+	buf := &bytes.Buffer{}
+	buf.WriteString(frontMatterDelimiter)
+	buf.Write(head)
+	buf.WriteString(frontMatterDelimiter)
+	buf.WriteString(body)
+	return buf.Bytes(), nil
+}
+
+func readFrontMatter(path string, meta interface{}) (string, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	body, err := parseFrontMatter(data, meta)
+	if err != nil {
+		return "", fmt.Errorf("%s: %s", path, err.Error())
+	}
+	return body, nil
+}
+
+func (c *RemoteCommand) writeFrontMatter(path string, meta interface{}, body string) error {
+	data, err := formatFrontMatter(meta, body)
+	if err != nil {
+		return err
+	}
+	return c.writeFile(path, data)
+}
@@ -1,19 +1,37 @@
 package main
 
+import (
+	"fmt"
+	"io"
+)
+
 type GetCategory struct {
 	*RemoteCommand
 }
 
+func (c *GetCategory) Usage(w io.Writer, progname, cmdname string) {
+	fmt.Fprintf(w, "%s %s <category-slug>\n\n", progname, cmdname)
+	fmt.Fprintf(w, "Examples:\n\n")
+	fmt.Fprintf(w, "%s %s getting-started\n", progname, cmdname)
+}
+
 func (c *GetCategory) MinArguments() int {
 	return 1
 }
 
+func (c *GetCategory) HasResult(args []string) bool {
+	return true
+}
+
 func (c *GetCategory) Run(args []string) error {
 	cat := args[0]
-	res, err := c.client.Category(cat)
+	res, err := c.client.CategoryContext(c.ctx, cat)
 	if err != nil {
 		return err
 	}
+	if c.structured() {
+		return c.emit(newCategorySummary(res))
+	}
 	if c.client.Output != nil {
 		return nil
 	}
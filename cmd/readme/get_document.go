@@ -1,13 +1,29 @@
 package main
 
+import (
+	"fmt"
+	"io"
+)
+
 type GetDocument struct {
 	*RemoteCommand
 }
 
+func (c *GetDocument) Usage(w io.Writer, progname, cmdname string) {
+	fmt.Fprintf(w, "%s %s [slug]\n\n", progname, cmdname)
+	fmt.Fprintf(w, "The doc is chosen interactively if no slug is specified.\n\n")
+	fmt.Fprintf(w, "Examples:\n\n")
+	fmt.Fprintf(w, "%s %s quick-start\n", progname, cmdname)
+}
+
 func (c *GetDocument) MinArguments() int {
 	return 0
 }
 
+func (c *GetDocument) HasResult(args []string) bool {
+	return true
+}
+
 func (c *GetDocument) Run(args []string) error {
 	doc := ""
 	if len(args) > 0 {
@@ -28,10 +44,19 @@ func (c *GetDocument) Run(args []string) error {
 			return nil
 		}
 	}
-	res, err := c.client.Doc(doc)
+	res, err := c.client.DocContext(c.ctx, doc)
 	if err != nil {
 		return err
 	}
+	if c.structured() {
+		return c.emit(&docDetail{
+			docSummary: *newDocSummary(res),
+			ID:         res.ID,
+			Category:   res.Category,
+			Excerpt:    res.Excerpt,
+			Body:       res.Body,
+		})
+	}
 	if c.client.Output != nil {
 		return nil
 	}
@@ -1,18 +1,38 @@
 package main
 
+import (
+	"fmt"
+	"io"
+)
+
 type ListCategories struct {
 	*RemoteCommand
 }
 
+func (c *ListCategories) Usage(w io.Writer, progname, cmdname string) {
+	fmt.Fprintf(w, "%s %s\n", progname, cmdname)
+}
+
 func (c *ListCategories) MinArguments() int {
 	return 0
 }
 
+func (c *ListCategories) HasResult(args []string) bool {
+	return true
+}
+
 func (c *ListCategories) Run(args []string) error {
-	res, err := c.client.Categories()
+	res, err := c.client.CategoriesContext(c.ctx)
 	if err != nil {
 		return err
 	}
+	if c.structured() {
+		list := &categoryList{Categories: make([]*categorySummary, 0)}
+		for _, cat := range res {
+			list.Categories = append(list.Categories, newCategorySummary(cat))
+		}
+		return c.emit(list)
+	}
 	if c.client.Output != nil {
 		return nil
 	}
@@ -1,15 +1,34 @@
 package main
 
+import (
+	"fmt"
+	"io"
+)
+
 type ListDocuments struct {
 	*RemoteCommand
 }
 
+func (c *ListDocuments) Usage(w io.Writer, progname, cmdname string) {
+	fmt.Fprintf(w, "%s %s [category-slug]\n\n", progname, cmdname)
+	fmt.Fprintf(w, "The category is chosen interactively if not specified.\n\n")
+	fmt.Fprintf(w, "Examples:\n\n")
+	fmt.Fprintf(w, "%s %s getting-started\n", progname, cmdname)
+}
+
 func (c *ListDocuments) MinArguments() int {
-	return 1
+	return 0
+}
+
+func (c *ListDocuments) HasResult(args []string) bool {
+	return true
 }
 
 func (c *ListDocuments) Run(args []string) error {
-	category := args[0]
+	category := ""
+	if len(args) > 0 {
+		category = args[0]
+	}
 	if category == "" {
 		var err error
 		category, err = c.chooseCategory(false)
@@ -20,10 +39,20 @@ func (c *ListDocuments) Run(args []string) error {
 			return nil
 		}
 	}
-	docs, err := c.client.Docs(category)
+	docs, err := c.client.DocsContext(c.ctx, category)
 	if err != nil {
 		return err
 	}
+	if c.structured() {
+		res := &docList{
+			Category: category,
+			Docs:     make([]*docSummary, 0),
+		}
+		for _, d := range docs {
+			res.Docs = append(res.Docs, newDocSummary(d))
+		}
+		return c.emit(res)
+	}
 	if c.client.Output != nil {
 		return nil
 	}
@@ -1,14 +1,17 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"io"
-	"log"
 	"os"
+	"os/signal"
 	"path/filepath"
 	"strings"
 	"syscall"
+	"text/tabwriter"
+	"time"
 
 	"github.com/cedricshih/readme/api/readme"
 )
@@ -17,93 +20,282 @@ const (
 	localConfig = "local.yaml"
 )
 
+const (
+	exitOK      = 0
+	exitFailure = 1
+	exitUsage   = int(syscall.EINVAL)
+)
+
 var args = &struct {
 	apiKey    string
 	help      bool
 	rawOutput bool
-}{}
+	quiet     bool
+	verbose   bool
+	trace     bool
+	timeout   time.Duration
+	retries   int
+	rate      float64
+}{
+	timeout: time.Minute,
+	retries: readme.DefaultRetryPolicy().MaxRetries,
+}
+
+// ratePerJob is the default of -l per concurrent fetch, so that -j speeds
+// up pulls without hitting the rate limit of ReadMe, which throttles the
+// client further by x-ratelimit-remaining and Retry-After anyway.
+const ratePerJob = 10
 
 var remoteCommand = &RemoteCommand{
-	input:  os.Stdin,
-	output: os.Stdout,
+	ctx:         context.Background(),
+	input:       os.Stdin,
+	output:      os.Stdout,
+	jobs:        4,
+	interactive: isTerminal(os.Stdin),
+	format:      formatTable,
+	result:      os.Stdout,
 }
 
-var commands = map[string]Command{
-	// "cat":        &GetCategory{RemoteCommand: rc},
-	// "cats":       &ListCategories{RemoteCommand: rc},
-	// "categories": &ListCategories{RemoteCommand: rc},
-	// "docs":       &ListDocuments{RemoteCommand: rc},
-	// "doc":        &GetDocument{RemoteCommand: rc},
-	"pull": &PullDocument{remoteCommand},
-	// "push":       &PushDocument{RemoteCommand: rc},
-	// "clone":      &PushDocument{RemoteCommand: rc},
+var commands = Registry{
+	{Name: "categories", Aliases: []string{"cats"}, Summary: "List categories", Command: &ListCategories{remoteCommand}},
+	{Name: "cat", Summary: "Show a category", Command: &GetCategory{remoteCommand}},
+	{Name: "category", Summary: "Create, rename or delete a category", Command: &ManageCategory{remoteCommand}},
+	{Name: "docs", Summary: "List docs in a category", Command: &ListDocuments{remoteCommand}},
+	{Name: "doc", Summary: "Show a doc", Command: &GetDocument{remoteCommand}},
+	{Name: "migrate", Summary: "Convert local docs to Markdown with front matter", Command: &Migrate{remoteCommand}},
+	{Name: "pull", Summary: "Pull a doc", Command: &PullDocument{remoteCommand}},
+	{Name: "pull-category", Summary: "Pull docs of a category", Command: &PullCategory{remoteCommand}},
+	{Name: "push", Summary: "Push a doc, creating it if needed", Command: &PushDocument{remoteCommand}},
+	{Name: "mv", Aliases: []string{"move"}, Summary: "Move a doc to another category", Command: &MoveDocument{remoteCommand}},
+	{Name: "rename", Summary: "Change the slug of a doc and rewrite links to it", Command: &RenameDocument{remoteCommand}},
+	{Name: "delete", Aliases: []string{"rm"}, Summary: "Delete a doc", Command: &DeleteDocument{remoteCommand}},
+	{Name: "sync", Aliases: []string{"clone"}, Summary: "Synchronize all docs of the project", Command: &Synchronize{remoteCommand}},
+	{Name: "status", Summary: "Show the sync state of every doc", Command: &Status{RemoteCommand: remoteCommand}},
+	{Name: "changelog", Summary: "List, pull, push or delete changelog posts", Command: &ManageChangelog{remoteCommand}},
+	{Name: "custompage", Aliases: []string{"pages"}, Summary: "List, pull, push or delete custom pages", Command: &ManageCustomPage{remoteCommand}},
+	{Name: "spec", Aliases: []string{"openapi"}, Summary: "List, push or delete OpenAPI definitions", Command: &ManageSpec{remoteCommand}},
+	{Name: "version", Summary: "List, create or delete project versions", Command: &ManageVersion{remoteCommand}},
+}
+
+func isFlagSet(name string) bool {
+	set := false
+	flag.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			set = true
+		}
+	})
+	return set
 }
 
 func usage(w io.Writer, fmtsrt string, args ...interface{}) {
-	fmt.Fprintf(w, "%s [args...] <command>\n", filepath.Base(os.Args[0]))
-	flag.Usage()
-	names := []string{}
-	for k := range commands {
-		names = append(names, k)
+	progname := filepath.Base(os.Args[0])
+	fmt.Fprintf(w, "%s [args...] <command> [command args...]\n", progname)
+	flag.PrintDefaults()
+	fmt.Fprintf(w, "\nAvailable commands:\n")
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	for _, sub := range commands.Sorted() {
+		name := sub.Name
+		if len(sub.Aliases) > 0 {
+			name = fmt.Sprintf("%s (%s)", name, strings.Join(sub.Aliases, ", "))
+		}
+		fmt.Fprintf(tw, "  %s\t%s\n", name, sub.Summary)
 	}
-	fmt.Fprintf(w, "Available commands: %s\n", strings.Join(names, ", "))
+	tw.Flush()
+	fmt.Fprintf(w, "\nRun '%s help <command>' for details of a command.\n", progname)
 	if fmtsrt != "" {
 		fmt.Fprintf(os.Stderr, "ERROR: %s\n", fmt.Sprintf(fmtsrt, args...))
 	}
 }
 
+func commandUsage(w io.Writer, sub *Subcommand, fs *flag.FlagSet) {
+	progname := filepath.Base(os.Args[0])
+	fmt.Fprintf(w, "%s\n\n", sub.Summary)
+	sub.Command.Usage(w, progname, sub.Name)
+	if len(sub.Aliases) > 0 {
+		fmt.Fprintf(w, "\nAliases: %s\n", strings.Join(sub.Aliases, ", "))
+	}
+	hasFlags := false
+	fs.VisitAll(func(*flag.Flag) {
+		hasFlags = true
+	})
+	if hasFlags {
+		fmt.Fprintf(w, "\nFlags:\n")
+		fs.PrintDefaults()
+	}
+}
+
+func fail(format string, args ...interface{}) {
+	fmt.Fprintf(os.Stderr, "ERROR: %s\n", fmt.Sprintf(format, args...))
+	os.Exit(exitFailure)
+}
+
 func main() {
 	flag.BoolVar(&args.help, "h", args.help, "help")
 	flag.StringVar(&args.apiKey, "k", args.apiKey, "API Key")
 	flag.StringVar(&remoteCommand.docRoot, "d", remoteCommand.docRoot, "Document folder")
-	flag.BoolVar(&args.rawOutput, "j", args.rawOutput, "Output JSON response")
+	flag.StringVar(&remoteCommand.version, "v", remoteCommand.version, "Project version, e.g. 1.0")
+	flag.BoolVar(&args.quiet, "q", args.quiet, "Log errors only")
+	flag.BoolVar(&args.verbose, "verbose", args.verbose, "Log every API request with its status and timing")
+	flag.BoolVar(&args.trace, "vv", args.trace, "Log HTTP headers of API requests too, with the API key redacted")
+	flag.BoolVar(&args.rawOutput, "raw", args.rawOutput, "Dump raw HTTP bodies for debugging, which was -j before -j took the number of concurrent fetches")
+	flag.StringVar(&remoteCommand.format, "o", remoteCommand.format, "Output format of results: json, yaml or table. Only commands listing or showing things, status, push, pull, pull-category, sync, mv, rename and delete have results")
+	flag.StringVar(&remoteCommand.format, "output", remoteCommand.format, "Same as -o")
+	flag.IntVar(&remoteCommand.jobs, "j", remoteCommand.jobs, fmt.Sprintf("Number of concurrent fetches from ReadMe, limited by -l which is %d per job unless set.\nRaw JSON output, which -j used to be for, is now -raw", ratePerJob))
 	flag.BoolVar(&remoteCommand.allYes, "y", remoteCommand.allYes, "'Yes' to all prompts")
+	flag.BoolVar(&remoteCommand.noInput, "no-input", remoteCommand.noInput, "Fail instead of prompting, e.g. in CI")
+	flag.BoolVar(&remoteCommand.dryRun, "n", remoteCommand.dryRun, "Dry run, print planned changes instead of making them")
+	flag.BoolVar(&remoteCommand.dryRun, "dry-run", remoteCommand.dryRun, "Same as -n")
+	flag.DurationVar(&args.timeout, "t", args.timeout, "Timeout of each API request, 0 for none")
+	flag.IntVar(&args.retries, "r", args.retries, "Maximum retries of a failed API request")
+	flag.Float64Var(&args.rate, "l", args.rate, fmt.Sprintf("Maximum API requests per second, 0 for unlimited (default %d times -j)", ratePerJob))
 	flag.Parse()
 	out := flag.CommandLine.Output()
 	cmdname := flag.Arg(0)
-	if args.help && cmdname == "" {
-		usage(out, "")
+	cmdargs := flag.Args()
+	if !isFlagSet("l") {
+		args.rate = float64(ratePerJob * remoteCommand.jobs)
+	}
+	if cmdname == "help" {
+		if len(cmdargs) < 2 {
+			usage(out, "")
+			return
+		}
+		args.help = true
+		cmdname = cmdargs[1]
+		cmdargs = cmdargs[1:]
+	}
+	if cmdname == "" {
+		if args.help {
+			usage(out, "")
+			return
+		}
+		usage(out, "Missing command")
+		os.Exit(exitUsage)
+	}
+	switch remoteCommand.format {
+	case formatTable, formatJSON, formatYAML:
+	default:
+		usage(out, "Unknown output format: %s", remoteCommand.format)
+		os.Exit(exitUsage)
+	}
+	if remoteCommand.structured() {
+		// keep stdout for the result document
+		remoteCommand.output = os.Stderr
+	}
+	sub := commands.Lookup(cmdname)
+	if sub == nil {
+		usage(out, "Unknown command: %s", cmdname)
+		os.Exit(exitUsage)
+	}
+	fs := flag.NewFlagSet(sub.Name, flag.ContinueOnError)
+	fs.SetOutput(out)
+	help := false
+	fs.BoolVar(&help, "h", help, "help")
+	if fc, ok := sub.Command.(FlagCommand); ok {
+		fc.Flags(fs)
+	}
+	fs.Usage = func() {
+		commandUsage(out, sub, fs)
+	}
+	err := fs.Parse(cmdargs[1:])
+	if err == flag.ErrHelp {
 		return
 	}
+	if err != nil {
+		os.Exit(exitUsage)
+	}
+	if help || args.help {
+		commandUsage(out, sub, fs)
+		return
+	}
+	if fs.NArg() < sub.Command.MinArguments() {
+		commandUsage(out, sub, fs)
+		fmt.Fprintf(os.Stderr, "ERROR: Missing argument(s): expect=%d, actual=%d\n", sub.Command.MinArguments(), fs.NArg())
+		os.Exit(exitUsage)
+	}
+	if remoteCommand.structured() {
+		rc, ok := sub.Command.(ResultCommand)
+		if !ok || !rc.HasResult(fs.Args()) {
+			fmt.Fprintf(os.Stderr, "ERROR: '%s' has no result to output as %s, please run it without -o\n", strings.Join(cmdargs, " "), remoteCommand.format)
+			os.Exit(exitUsage)
+		}
+	}
+	level := readme.LevelInfo
+	switch {
+	case args.trace:
+		level = readme.LevelTrace
+	case args.verbose:
+		level = readme.LevelDebug
+	case args.quiet:
+		level = readme.LevelError
+	}
+	remoteCommand.log = readme.NewLogger(os.Stderr, level)
 	cfg, err := ReadLocalConfig(localConfig)
 	if err != nil {
-		panic(err)
+		fail("Unable to read %s: %s", localConfig, err.Error())
 	}
 	if cfg.APIKey != "" {
-		log.Printf("Using API key from %s: %s", localConfig, cfg.APIKey)
+		remoteCommand.logf(readme.LevelInfo, "Using API key from %s: %s", localConfig, readme.Redact(cfg.APIKey))
 		args.apiKey = cfg.APIKey
 	}
 	if cfg.DocRoot != "" {
-		log.Printf("Using doc root from %s: %s", localConfig, cfg.DocRoot)
+		remoteCommand.logf(readme.LevelInfo, "Using doc root from %s: %s", localConfig, cfg.DocRoot)
 		remoteCommand.docRoot = cfg.DocRoot
 	}
 	if args.apiKey == "" {
 		usage(out, "API Key is not specified either by command line argument or in 'local.yaml'")
-		os.Exit(int(syscall.EINVAL))
-	}
-	if cmdname == "" {
-		usage(out, "Missing command")
-		os.Exit(int(syscall.EINVAL))
+		os.Exit(exitUsage)
 	}
 	remoteCommand.client = readme.NewClient(args.apiKey)
+	remoteCommand.client.Version = remoteCommand.version
+	remoteCommand.client.Logger = remoteCommand.log
+	remoteCommand.client.RequestTimeout = args.timeout
+	remoteCommand.client.Retry.MaxRetries = args.retries
+	remoteCommand.client.Limiter = readme.NewRateLimiter(args.rate)
+	remoteCommand.client.DryRun = remoteCommand.dryRun
 	if args.rawOutput {
-		remoteCommand.client.Output = os.Stdout
+		remoteCommand.client.Output = remoteCommand.output
 	}
-	cmd := commands[cmdname]
-	if cmd == nil {
-		usage(out, "Unknown command: %s", cmdname)
-		os.Exit(int(syscall.EINVAL))
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		// Restore the default behavior so that a second signal kills the
+		// process immediately.
+		stop()
+	}()
+	remoteCommand.ctx = ctx
+	err = sub.Command.Run(fs.Args())
+	remoteCommand.printPlan()
+	if err != nil && ctx.Err() != nil {
+		fmt.Fprintf(os.Stderr, "ERROR: Command '%s' interrupted: %s\n", sub.Name, err.Error())
+		os.Exit(exitFailure)
+	}
+	if err != nil {
+		printErrorHints(err)
+		fail("Command '%s' failed: %s", sub.Name, err.Error())
 	}
-	if args.help {
-		cmd.Usage(out, os.Args[0], flag.Arg(0))
+	os.Exit(exitOK)
+}
+
+func printErrorHints(err error) {
+	e := readme.AsError(err)
+	if e == nil {
 		return
 	}
-	if len(flag.Args())-1 < cmd.MinArguments() {
-		usage(out, "Missing argument(s): expect=%d, actual=%d", cmd.MinArguments(), len(flag.Args())-1)
-		os.Exit(int(syscall.EINVAL))
+	switch {
+	case readme.IsUnauthorized(err):
+		fmt.Fprintf(os.Stderr, "HINT: Check the API key given by -k or in '%s'\n", localConfig)
+	case readme.IsRateLimited(err):
+		fmt.Fprintf(os.Stderr, "HINT: ReadMe rate limit exceeded, retry later or lower -l\n")
 	}
-	err = cmd.Run(flag.Args()[1:])
-	if err != nil {
-		log.Printf("Command '%s' failed: %s", cmdname, err.Error())
+	if e.Suggestion != "" {
+		fmt.Fprintf(os.Stderr, "HINT: %s\n", e.Suggestion)
+	}
+	if e.Help != "" {
+		fmt.Fprintf(os.Stderr, "HINT: %s\n", e.Help)
+	}
+	if e.RequestID != "" {
+		fmt.Fprintf(os.Stderr, "Request ID: %s\n", e.RequestID)
 	}
 }
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/cedricshih/readme/api/readme"
+)
+
+type ManageCategory struct {
+	*RemoteCommand
+}
+
+func (c *ManageCategory) Usage(w io.Writer, progname, cmdname string) {
+	fmt.Fprintf(w, "%s %s create <title> [guide|reference]\n", progname, cmdname)
+	fmt.Fprintf(w, "%s %s rename <slug> <title>\n", progname, cmdname)
+	fmt.Fprintf(w, "%s %s delete <slug>\n\n", progname, cmdname)
+	fmt.Fprintf(w, "Examples:\n\n")
+	fmt.Fprintf(w, "%s %s create 'Getting Started'\n", progname, cmdname)
+	fmt.Fprintf(w, "%s %s rename getting-started 'Quick Start'\n", progname, cmdname)
+	fmt.Fprintf(w, "%s %s delete getting-started\n", progname, cmdname)
+}
+
+func (c *ManageCategory) MinArguments() int {
+	return 2
+}
+
+func (c *ManageCategory) Run(args []string) error {
+	switch args[0] {
+	case "create":
+		typ := readme.CategoryTypeGuide
+		if len(args) > 2 {
+			typ = args[2]
+		}
+		return c.create(args[1], typ)
+	case "rename":
+		if len(args) < 3 {
+			return fmt.Errorf("missing new title of category '%s'", args[1])
+		}
+		return c.rename(args[1], args[2])
+	case "delete":
+		return c.delete(args[1])
+	default:
+		return fmt.Errorf("unknown category command: %s", args[0])
+	}
+}
+
+func (c *ManageCategory) create(title, typ string) error {
+	if typ != readme.CategoryTypeGuide && typ != readme.CategoryTypeReference {
+		return fmt.Errorf("invalid category type: %s", typ)
+	}
+	meta, err := c.metadata()
+	if err != nil {
+		return err
+	}
+	c.printf("Creating category on ReadMe: %s", title)
+	res, err := c.client.CreateCategoryContext(c.ctx, &readme.Category{
+		Title: title,
+		Type:  typ,
+	})
+	if err != nil {
+		return err
+	}
+	meta.AddCategory(res.Slug, res.ID)
+	err = c.mkdirAll(c.categoryPath(res.Slug))
+	if err != nil {
+		return err
+	}
+	c.printf("Category '%s' is created: %s", res.Slug, res.ID)
+	return c.writeMetadata(meta)
+}
+
+func (c *ManageCategory) rename(slug, title string) error {
+	meta, err := c.metadata()
+	if err != nil {
+		return err
+	}
+	old, err := c.client.CategoryContext(c.ctx, slug)
+	if err != nil {
+		return err
+	}
+	cont, err := c.yesOrNo("Are you sure to rename category '%s' from '%s' to '%s'?", slug, old.Title, title)
+	if err != nil {
+		return err
+	}
+	if !cont {
+		c.printf("Category '%s' is not renamed", slug)
+		return nil
+	}
+	res, err := c.client.UpdateCategoryContext(c.ctx, slug, &readme.Category{
+		Title: title,
+		Type:  old.Type,
+	})
+	if err != nil {
+		return err
+	}
+	if res.Slug != slug && meta.Categories[slug] != nil {
+		oldPath := c.categoryPath(slug)
+		newPath := c.categoryPath(res.Slug)
+		c.printf("Category '%s' is now '%s', renaming: %s => %s", slug, res.Slug, oldPath, newPath)
+		err = c.rename(oldPath, newPath)
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		meta.Categories[res.Slug] = meta.Categories[slug]
+		delete(meta.Categories, slug)
+		for _, doc := range meta.Categories[res.Slug].DocKeys() {
+			err = c.updatePlacement(res.Slug, doc)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	c.printf("Category '%s' is renamed to: %s", res.Slug, res.Title)
+	return c.writeMetadata(meta)
+}
+
+func (c *ManageCategory) delete(slug string) error {
+	meta, err := c.metadata()
+	if err != nil {
+		return err
+	}
+	cont, err := c.confirmDestructive("Are you sure to delete category '%s' from remote?", slug)
+	if err != nil {
+		return err
+	}
+	if !cont {
+		c.printf("Category '%s' is not deleted", slug)
+		return nil
+	}
+	c.printf("Deleting category from ReadMe: %s", slug)
+	err = c.client.DeleteCategoryContext(c.ctx, slug)
+	if err != nil {
+		return err
+	}
+	if meta.Categories[slug] == nil {
+		return nil
+	}
+	delete(meta.Categories, slug)
+	c.printf("Local docs are kept in: %s", c.categoryPath(slug))
+	return c.writeMetadata(meta)
+}
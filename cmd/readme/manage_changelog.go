@@ -0,0 +1,281 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/cedricshih/readme/api/readme"
+	"github.com/sergi/go-diff/diffmatchpatch"
+)
+
+type changelogFrontMatter struct {
+	Title  string `yaml:"title"`
+	Type   string `yaml:"type,omitempty"`
+	Hidden bool   `yaml:"hidden"`
+}
+
+type ManageChangelog struct {
+	*RemoteCommand
+}
+
+func (c *ManageChangelog) Usage(w io.Writer, progname, cmdname string) {
+	fmt.Fprintf(w, "%s %s list\n", progname, cmdname)
+	fmt.Fprintf(w, "%s %s pull [slug]\n", progname, cmdname)
+	fmt.Fprintf(w, "%s %s push <slug-like>\n", progname, cmdname)
+	fmt.Fprintf(w, "%s %s delete <slug>\n\n", progname, cmdname)
+	fmt.Fprintf(w, "Changelog posts are stored as Markdown files with front matter in '%s'.\n", c.changelogPath())
+	fmt.Fprintf(w, "All posts are pulled if no slug is specified, and a post is created if not found on remote.\n\n")
+	fmt.Fprintf(w, "Examples:\n\n")
+	fmt.Fprintf(w, "%s %s pull\n", progname, cmdname)
+	fmt.Fprintf(w, "%s %s push changelog/release-v1-2.md\n", progname, cmdname)
+}
+
+func (c *ManageChangelog) MinArguments() int {
+	return 1
+}
+
+func (c *ManageChangelog) HasResult(args []string) bool {
+	return args[0] == "list"
+}
+
+func (c *ManageChangelog) Run(args []string) error {
+	slug := ""
+	if len(args) > 1 {
+		slug = args[1]
+		slug = filepath.Base(slug)
+		slug = strings.TrimSuffix(slug, filepath.Ext(slug))
+	}
+	switch args[0] {
+	case "list":
+		return c.list()
+	case "pull":
+		if slug == "" {
+			return c.pullAll()
+		}
+		return c.pull(slug)
+	case "push":
+		if slug == "" {
+			return fmt.Errorf("missing changelog to push")
+		}
+		return c.push(slug)
+	case "delete":
+		if slug == "" {
+			return fmt.Errorf("missing changelog to delete")
+		}
+		return c.delete(slug)
+	default:
+		return fmt.Errorf("unknown changelog command: %s", args[0])
+	}
+}
+
+func (c *ManageChangelog) changelogPath() string {
+	return filepath.Join(c.docRoot, "changelog")
+}
+
+func (c *ManageChangelog) changelogFilePath(slug string) string {
+	return filepath.Join(c.changelogPath(), fmt.Sprintf("%s.md", slug))
+}
+
+func (c *ManageChangelog) list() error {
+	res, err := c.client.ChangelogsContext(c.ctx)
+	if err != nil {
+		return err
+	}
+	if c.structured() {
+		list := &changelogList{Changelogs: make([]*changelogSummary, 0)}
+		for _, l := range res {
+			list.Changelogs = append(list.Changelogs, &changelogSummary{
+				Slug:      l.Slug,
+				Title:     l.Title,
+				Type:      l.Type,
+				Hidden:    l.Hidden,
+				CreatedAt: l.CreatedAt,
+			})
+		}
+		return c.emit(list)
+	}
+	if c.client.Output != nil {
+		return nil
+	}
+	c.printf("Got %d changelogs:", len(res))
+	for _, l := range res {
+		if l.Hidden {
+			c.printf("- %s : %s (hidden)", l.Slug, l.Title)
+		} else {
+			c.printf("- %s : %s", l.Slug, l.Title)
+		}
+	}
+	return nil
+}
+
+func (c *ManageChangelog) local(slug string) (*readme.Changelog, error) {
+	fm := &changelogFrontMatter{}
+	body, err := readFrontMatter(c.changelogFilePath(slug), fm)
+	if err != nil {
+		return nil, err
+	}
+	return &readme.Changelog{
+		Slug:   slug,
+		Title:  fm.Title,
+		Type:   fm.Type,
+		Hidden: fm.Hidden,
+		Body:   body,
+	}, nil
+}
+
+func (c *ManageChangelog) save(log *readme.Changelog) error {
+	path := c.changelogFilePath(log.Slug)
+	c.printf("Writing changelog: %s", path)
+	return c.writeFrontMatter(path, &changelogFrontMatter{
+		Title:  log.Title,
+		Type:   log.Type,
+		Hidden: log.Hidden,
+	}, log.Body)
+}
+
+func (c *ManageChangelog) diff(old, new *readme.Changelog) bool {
+	c.printf("Checking '%s' for difference...", old.Slug)
+	diff := false
+	if old.Title != new.Title {
+		c.printf("Title: %s => %s", old.Title, new.Title)
+		diff = true
+	}
+	if old.Type != new.Type {
+		c.printf("Type: %s => %s", old.Type, new.Type)
+		diff = true
+	}
+	if old.Hidden != new.Hidden {
+		c.printf("Hidden: %v => %v", old.Hidden, new.Hidden)
+		diff = true
+	}
+	if old.Body != new.Body {
+		c.printf("Body:")
+		dmp := diffmatchpatch.New()
+		diffs := dmp.DiffMain(old.Body, new.Body, false)
+		c.printf("%s", dmp.DiffPrettyText(diffs))
+		diff = true
+	}
+	return diff
+}
+
+func (c *ManageChangelog) pullAll() error {
+	res, err := c.client.ChangelogsContext(c.ctx)
+	if err != nil {
+		return err
+	}
+	for _, l := range res {
+		err = c.pull(l.Slug)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *ManageChangelog) pull(slug string) error {
+	remote, err := c.client.ChangelogContext(c.ctx, slug)
+	if err != nil {
+		return err
+	}
+	old, err := c.local(slug)
+	if err == nil {
+		if !c.diff(old, remote) {
+			c.printf("Changelog '%s' is not changed", slug)
+			return nil
+		}
+		cont, err := c.confirmDestructive("Are you sure to pull changelog '%s' and overwrite local changes?", slug)
+		if err != nil {
+			return err
+		}
+		if !cont {
+			c.printf("Changelog '%s' is not pulled", slug)
+			return nil
+		}
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+	return c.save(remote)
+}
+
+func (c *ManageChangelog) push(slug string) error {
+	new, err := c.local(slug)
+	if err != nil {
+		return err
+	}
+	if new.Title == "" {
+		return fmt.Errorf("missing title in front matter: %s", c.changelogFilePath(slug))
+	}
+	old, err := c.client.ChangelogContext(c.ctx, slug)
+	if err != nil {
+		if !readme.IsNotFound(err) {
+			return err
+		}
+		cont, err := c.yesOrNo("Changelog '%s' does not exist on remote, are you sure to create it?", slug)
+		if err != nil {
+			return err
+		}
+		if !cont {
+			c.printf("Changelog '%s' is not created", slug)
+			return nil
+		}
+		res, err := c.client.CreateChangelogContext(c.ctx, new)
+		if err != nil {
+			return err
+		}
+		if res.Slug != slug {
+			// ReadMe derives the slug from the title
+			oldPath := c.changelogFilePath(slug)
+			newPath := c.changelogFilePath(res.Slug)
+			c.printf("Changelog '%s' is created as '%s', renaming: %s => %s", slug, res.Slug, oldPath, newPath)
+			err = c.rename(oldPath, newPath)
+			if err != nil {
+				return err
+			}
+		}
+		c.printf("Changelog '%s' is created", res.Slug)
+		return nil
+	}
+	if !c.diff(old, new) {
+		c.printf("Changelog '%s' is unchanged", slug)
+		return nil
+	}
+	cont, err := c.yesOrNo("Are you sure to push changelog '%s' to remote?", slug)
+	if err != nil {
+		return err
+	}
+	if !cont {
+		c.printf("Changelog '%s' is not pushed", slug)
+		return nil
+	}
+	_, err = c.client.UpdateChangelogContext(c.ctx, slug, new)
+	if err != nil {
+		return err
+	}
+	c.printf("Changelog '%s' is pushed", slug)
+	return nil
+}
+
+func (c *ManageChangelog) delete(slug string) error {
+	cont, err := c.confirmDestructive("Are you sure to delete changelog '%s' from remote?", slug)
+	if err != nil {
+		return err
+	}
+	if !cont {
+		c.printf("Changelog '%s' is not deleted", slug)
+		return nil
+	}
+	err = c.client.DeleteChangelogContext(c.ctx, slug)
+	if err != nil && !readme.IsNotFound(err) {
+		return err
+	}
+	path := c.changelogFilePath(slug)
+	c.printf("Removing changelog: %s", path)
+	err = c.remove(path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
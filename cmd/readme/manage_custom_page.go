@@ -0,0 +1,344 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/cedricshih/readme/api/readme"
+	"github.com/cedricshih/readme/repository"
+	"github.com/sergi/go-diff/diffmatchpatch"
+)
+
+type ManageCustomPage struct {
+	*RemoteCommand
+}
+
+func (c *ManageCustomPage) Usage(w io.Writer, progname, cmdname string) {
+	fmt.Fprintf(w, "%s %s list\n", progname, cmdname)
+	fmt.Fprintf(w, "%s %s pull [slug]\n", progname, cmdname)
+	fmt.Fprintf(w, "%s %s push <slug-like>\n", progname, cmdname)
+	fmt.Fprintf(w, "%s %s delete <slug>\n\n", progname, cmdname)
+	fmt.Fprintf(w, "Custom pages are stored in 'custompages/<slug>.md' (and '<slug>.html' in HTML mode) and tracked in metadata.\n")
+	fmt.Fprintf(w, "All pages are pulled if no slug is specified, and a page is created if not found on remote.\n\n")
+	fmt.Fprintf(w, "Examples:\n\n")
+	fmt.Fprintf(w, "%s %s pull terms-of-service\n", progname, cmdname)
+	fmt.Fprintf(w, "%s %s push custompages/terms-of-service.md\n", progname, cmdname)
+}
+
+func (c *ManageCustomPage) MinArguments() int {
+	return 1
+}
+
+func (c *ManageCustomPage) HasResult(args []string) bool {
+	return args[0] == "list"
+}
+
+func (c *ManageCustomPage) Run(args []string) error {
+	slug := ""
+	if len(args) > 1 {
+		slug = args[1]
+		slug = filepath.Base(slug)
+		slug = strings.TrimSuffix(slug, filepath.Ext(slug))
+	}
+	if args[0] == "list" {
+		return c.list()
+	}
+	meta, err := c.metadata()
+	if err != nil {
+		return err
+	}
+	root := meta.Root()
+	if root.CustomPages == nil {
+		root.CustomPages = make(map[string]*repository.CustomPage)
+	}
+	changed := false
+	switch args[0] {
+	case "pull":
+		if slug == "" {
+			changed, err = c.pullAll(root)
+		} else {
+			changed, err = c.pull(root, slug)
+		}
+	case "push":
+		if slug == "" {
+			return fmt.Errorf("missing custom page to push")
+		}
+		changed, err = c.push(root, slug)
+	case "delete":
+		if slug == "" {
+			return fmt.Errorf("missing custom page to delete")
+		}
+		changed, err = c.delete(root, slug)
+	default:
+		return fmt.Errorf("unknown custom page command: %s", args[0])
+	}
+	if err != nil {
+		return err
+	}
+	if changed {
+		return c.writeMetadata(root)
+	}
+	return nil
+}
+
+func (c *ManageCustomPage) customPagePath() string {
+	return filepath.Join(c.docRoot, "custompages")
+}
+
+func (c *ManageCustomPage) bodyFilePath(slug string) string {
+	return filepath.Join(c.customPagePath(), fmt.Sprintf("%s.md", slug))
+}
+
+func (c *ManageCustomPage) htmlFilePath(slug string) string {
+	return filepath.Join(c.customPagePath(), fmt.Sprintf("%s.html", slug))
+}
+
+func (c *ManageCustomPage) list() error {
+	res, err := c.client.CustomPagesContext(c.ctx)
+	if err != nil {
+		return err
+	}
+	if c.structured() {
+		list := &customPageList{CustomPages: make([]*customPageSummary, 0)}
+		for _, p := range res {
+			list.CustomPages = append(list.CustomPages, &customPageSummary{
+				Slug:     p.Slug,
+				Title:    p.Title,
+				Hidden:   p.Hidden,
+				HTMLMode: p.HTMLMode,
+			})
+		}
+		return c.emit(list)
+	}
+	if c.client.Output != nil {
+		return nil
+	}
+	c.printf("Got %d custom pages:", len(res))
+	for _, p := range res {
+		if p.Hidden {
+			c.printf("- %s : %s (hidden)", p.Slug, p.Title)
+		} else {
+			c.printf("- %s : %s", p.Slug, p.Title)
+		}
+	}
+	return nil
+}
+
+func (c *ManageCustomPage) local(meta *repository.Metadata, slug string) (*readme.CustomPage, error) {
+	pageMeta := meta.CustomPages[slug]
+	if pageMeta == nil {
+		return nil, os.ErrNotExist
+	}
+	body, err := ioutil.ReadFile(c.bodyFilePath(slug))
+	if err != nil {
+		return nil, err
+	}
+	html, err := ioutil.ReadFile(c.htmlFilePath(slug))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	return &readme.CustomPage{
+		Slug:     slug,
+		Title:    pageMeta.Title,
+		Body:     string(body),
+		HTML:     string(html),
+		HTMLMode: pageMeta.HTMLMode,
+		Hidden:   pageMeta.Hidden,
+	}, nil
+}
+
+func (c *ManageCustomPage) save(meta *repository.Metadata, page *readme.CustomPage) error {
+	meta.CustomPages[page.Slug] = &repository.CustomPage{
+		Title:    page.Title,
+		HTMLMode: page.HTMLMode,
+		Hidden:   page.Hidden,
+	}
+	err := c.mkdirAll(c.customPagePath())
+	if err != nil {
+		return err
+	}
+	path := c.bodyFilePath(page.Slug)
+	c.printf("Writing custom page: %s", path)
+	err = c.writeFile(path, []byte(page.Body))
+	if err != nil {
+		return err
+	}
+	path = c.htmlFilePath(page.Slug)
+	if page.HTML == "" && !page.HTMLMode {
+		err = c.remove(path)
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+	c.printf("Writing custom page: %s", path)
+	return c.writeFile(path, []byte(page.HTML))
+}
+
+func (c *ManageCustomPage) diff(old, new *readme.CustomPage) bool {
+	c.printf("Checking '%s' for difference...", old.Slug)
+	diff := false
+	if old.Title != new.Title {
+		c.printf("Title: %s => %s", old.Title, new.Title)
+		diff = true
+	}
+	if old.HTMLMode != new.HTMLMode {
+		c.printf("HTMLMode: %v => %v", old.HTMLMode, new.HTMLMode)
+		diff = true
+	}
+	if old.Hidden != new.Hidden {
+		c.printf("Hidden: %v => %v", old.Hidden, new.Hidden)
+		diff = true
+	}
+	dmp := diffmatchpatch.New()
+	if old.Body != new.Body {
+		c.printf("Body:")
+		diffs := dmp.DiffMain(old.Body, new.Body, false)
+		c.printf("%s", dmp.DiffPrettyText(diffs))
+		diff = true
+	}
+	if old.HTML != new.HTML {
+		c.printf("HTML:")
+		diffs := dmp.DiffMain(old.HTML, new.HTML, false)
+		c.printf("%s", dmp.DiffPrettyText(diffs))
+		diff = true
+	}
+	return diff
+}
+
+func (c *ManageCustomPage) pullAll(meta *repository.Metadata) (bool, error) {
+	res, err := c.client.CustomPagesContext(c.ctx)
+	if err != nil {
+		return false, err
+	}
+	changed := false
+	for _, p := range res {
+		chg, err := c.pull(meta, p.Slug)
+		if err != nil {
+			return false, err
+		}
+		changed = changed || chg
+	}
+	return changed, nil
+}
+
+func (c *ManageCustomPage) pull(meta *repository.Metadata, slug string) (bool, error) {
+	remote, err := c.client.CustomPageContext(c.ctx, slug)
+	if err != nil {
+		return false, err
+	}
+	old, err := c.local(meta, slug)
+	if err == nil {
+		if !c.diff(old, remote) {
+			c.printf("Custom page '%s' is not changed", slug)
+			return false, nil
+		}
+		cont, err := c.confirmDestructive("Are you sure to pull custom page '%s' and overwrite local changes?", slug)
+		if err != nil {
+			return false, err
+		}
+		if !cont {
+			c.printf("Custom page '%s' is not pulled", slug)
+			return false, nil
+		}
+	} else if !os.IsNotExist(err) {
+		return false, err
+	}
+	err = c.save(meta, remote)
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (c *ManageCustomPage) push(meta *repository.Metadata, slug string) (bool, error) {
+	if meta.CustomPages[slug] == nil {
+		c.printf("Custom page '%s' not found in '%s', please add it under 'CustomPages' and push again.", slug, c.metadataFilePath())
+		return false, nil
+	}
+	new, err := c.local(meta, slug)
+	if err != nil {
+		return false, err
+	}
+	old, err := c.client.CustomPageContext(c.ctx, slug)
+	if err != nil {
+		if !readme.IsNotFound(err) {
+			return false, err
+		}
+		cont, err := c.yesOrNo("Custom page '%s' does not exist on remote, are you sure to create it?", slug)
+		if err != nil {
+			return false, err
+		}
+		if !cont {
+			c.printf("Custom page '%s' is not created", slug)
+			return false, nil
+		}
+		res, err := c.client.CreateCustomPageContext(c.ctx, new)
+		if err != nil {
+			return false, err
+		}
+		if res.Slug != slug {
+			c.printf("Custom page '%s' is created as '%s'", slug, res.Slug)
+			for _, path := range []string{c.bodyFilePath(slug), c.htmlFilePath(slug)} {
+				err = c.remove(path)
+				if err != nil && !os.IsNotExist(err) {
+					return false, err
+				}
+			}
+			delete(meta.CustomPages, slug)
+			new.Slug = res.Slug
+			return true, c.save(meta, new)
+		}
+		c.printf("Custom page '%s' is created", slug)
+		return false, nil
+	}
+	if !c.diff(old, new) {
+		c.printf("Custom page '%s' is unchanged", slug)
+		return false, nil
+	}
+	cont, err := c.yesOrNo("Are you sure to push custom page '%s' to remote?", slug)
+	if err != nil {
+		return false, err
+	}
+	if !cont {
+		c.printf("Custom page '%s' is not pushed", slug)
+		return false, nil
+	}
+	_, err = c.client.UpdateCustomPageContext(c.ctx, slug, new)
+	if err != nil {
+		return false, err
+	}
+	c.printf("Custom page '%s' is pushed", slug)
+	return false, nil
+}
+
+func (c *ManageCustomPage) delete(meta *repository.Metadata, slug string) (bool, error) {
+	cont, err := c.confirmDestructive("Are you sure to delete custom page '%s' from remote?", slug)
+	if err != nil {
+		return false, err
+	}
+	if !cont {
+		c.printf("Custom page '%s' is not deleted", slug)
+		return false, nil
+	}
+	err = c.client.DeleteCustomPageContext(c.ctx, slug)
+	if err != nil && !readme.IsNotFound(err) {
+		return false, err
+	}
+	for _, path := range []string{c.bodyFilePath(slug), c.htmlFilePath(slug)} {
+		c.printf("Removing custom page: %s", path)
+		err = c.remove(path)
+		if err != nil && !os.IsNotExist(err) {
+			return false, err
+		}
+	}
+	if meta.CustomPages[slug] == nil {
+		return false, nil
+	}
+	delete(meta.CustomPages, slug)
+	return true, nil
+}
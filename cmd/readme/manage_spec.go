@@ -0,0 +1,202 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"github.com/cedricshih/readme/api/readme"
+	"gopkg.in/yaml.v2"
+)
+
+type ManageSpec struct {
+	*RemoteCommand
+}
+
+func (c *ManageSpec) Usage(w io.Writer, progname, cmdname string) {
+	fmt.Fprintf(w, "%s %s list\n", progname, cmdname)
+	fmt.Fprintf(w, "%s %s push <file>\n", progname, cmdname)
+	fmt.Fprintf(w, "%s %s delete <title-or-id>\n\n", progname, cmdname)
+	fmt.Fprintf(w, "An OpenAPI definition in JSON or YAML replaces the existing one with the same title, or is uploaded as a new one.\n\n")
+	fmt.Fprintf(w, "Examples:\n\n")
+	fmt.Fprintf(w, "%s %s push openapi/billing.yaml\n", progname, cmdname)
+}
+
+func (c *ManageSpec) MinArguments() int {
+	return 1
+}
+
+func (c *ManageSpec) HasResult(args []string) bool {
+	return args[0] == "list"
+}
+
+func (c *ManageSpec) Run(args []string) error {
+	switch args[0] {
+	case "list":
+		return c.list()
+	case "push":
+		if len(args) < 2 {
+			return fmt.Errorf("missing OpenAPI definition to push")
+		}
+		return c.push(args[1])
+	case "delete":
+		if len(args) < 2 {
+			return fmt.Errorf("missing OpenAPI definition to delete")
+		}
+		return c.delete(args[1])
+	default:
+		return fmt.Errorf("unknown spec command: %s", args[0])
+	}
+}
+
+func (c *ManageSpec) list() error {
+	res, err := c.client.APISpecificationsContext(c.ctx)
+	if err != nil {
+		return err
+	}
+	if c.structured() {
+		list := &specList{Specs: make([]*specSummary, 0)}
+		for _, s := range res {
+			spec := &specSummary{
+				ID:      s.ID,
+				Title:   s.Title,
+				Type:    s.Type,
+				Version: s.Version,
+			}
+			if s.Category != nil {
+				spec.Category = s.Category.Slug
+			}
+			list.Specs = append(list.Specs, spec)
+		}
+		return c.emit(list)
+	}
+	if c.client.Output != nil {
+		return nil
+	}
+	c.printf("Got %d API specifications:", len(res))
+	for _, s := range res {
+		c.printf("- %s : %s (%s)", s.ID, s.Title, specCategory(s))
+	}
+	return nil
+}
+
+func (c *ManageSpec) find(titleOrID string) (*readme.APISpecification, error) {
+	specs, err := c.client.APISpecificationsContext(c.ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, s := range specs {
+		if s.ID == titleOrID || s.Title == titleOrID {
+			return s, nil
+		}
+	}
+	return nil, nil
+}
+
+func (c *ManageSpec) push(path string) error {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	title, err := specTitle(path, data)
+	if err != nil {
+		return fmt.Errorf("invalid OpenAPI definition '%s': %s", path, err.Error())
+	}
+	old, err := c.find(title)
+	if err != nil {
+		return err
+	}
+	var res *readme.APISpecification
+	if old == nil {
+		c.printf("Uploading new API specification '%s': %s", title, path)
+		res, err = c.client.CreateAPISpecificationContext(c.ctx, path, data)
+	} else {
+		var cont bool
+		cont, err = c.yesOrNo("Are you sure to replace API specification '%s' (%s)?", title, old.ID)
+		if err != nil {
+			return err
+		}
+		if !cont {
+			c.printf("API specification '%s' is not pushed", title)
+			return nil
+		}
+		c.printf("Updating API specification '%s': %s", title, path)
+		res, err = c.client.UpdateAPISpecificationContext(c.ctx, old.ID, path, data)
+	}
+	if err != nil {
+		return err
+	}
+	if res.Category == nil {
+		updated, err := c.find(res.ID)
+		if err != nil {
+			return err
+		}
+		if updated != nil {
+			res = updated
+		}
+	}
+	c.printf("API specification '%s' is pushed: %s (%s)", title, res.ID, specCategory(res))
+	return nil
+}
+
+func (c *ManageSpec) delete(titleOrID string) error {
+	spec, err := c.find(titleOrID)
+	if err != nil {
+		return err
+	}
+	if spec == nil {
+		return fmt.Errorf("no such API specification: %s", titleOrID)
+	}
+	cont, err := c.confirmDestructive("Are you sure to delete API specification '%s' (%s) from remote?", spec.Title, spec.ID)
+	if err != nil {
+		return err
+	}
+	if !cont {
+		c.printf("API specification '%s' is not deleted", spec.Title)
+		return nil
+	}
+	return c.client.DeleteAPISpecificationContext(c.ctx, spec.ID)
+}
+
+func specCategory(spec *readme.APISpecification) string {
+	if spec.Category == nil {
+		return "no category"
+	}
+	return fmt.Sprintf("category: %s", spec.Category.Slug)
+}
+
+// specTitle validates an OpenAPI or Swagger definition and returns its
+// info.title.
+func specTitle(path string, data []byte) (string, error) {
+	doc := make(map[string]interface{})
+	var err error
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &doc)
+	default:
+		err = json.Unmarshal(data, &doc)
+	}
+	if err != nil {
+		return "", err
+	}
+	if doc["openapi"] == nil && doc["swagger"] == nil {
+		return "", fmt.Errorf("missing 'openapi' or 'swagger' version")
+	}
+	title := ""
+	switch info := doc["info"].(type) {
+	case map[string]interface{}:
+		title, _ = info["title"].(string)
+	case map[interface{}]interface{}:
+		title, _ = info["title"].(string)
+	}
+	if title == "" {
+		return "", fmt.Errorf("missing 'info.title'")
+	}
+	if doc["paths"] == nil {
+		return "", fmt.Errorf("missing 'paths'")
+	}
+	return title, nil
+}
@@ -0,0 +1,187 @@
+package main
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/cedricshih/readme/api/readme"
+)
+
+type ManageVersion struct {
+	*RemoteCommand
+}
+
+func (c *ManageVersion) Usage(w io.Writer, progname, cmdname string) {
+	fmt.Fprintf(w, "%s %s list\n", progname, cmdname)
+	fmt.Fprintf(w, "%s %s create <version> [from]\n", progname, cmdname)
+	fmt.Fprintf(w, "%s %s stable <version>\n", progname, cmdname)
+	fmt.Fprintf(w, "%s %s delete <version>\n\n", progname, cmdname)
+	fmt.Fprintf(w, "A new version is forked from the stable version unless [from] is specified.\n\n")
+	fmt.Fprintf(w, "Examples:\n\n")
+	fmt.Fprintf(w, "%s %s create 2.0\n", progname, cmdname)
+	fmt.Fprintf(w, "%s %s create 2.0 1.0\n", progname, cmdname)
+	fmt.Fprintf(w, "%s -v 2.0 pull quick-start\n", progname)
+}
+
+func (c *ManageVersion) MinArguments() int {
+	return 1
+}
+
+func (c *ManageVersion) HasResult(args []string) bool {
+	return args[0] == "list"
+}
+
+func (c *ManageVersion) Run(args []string) error {
+	switch args[0] {
+	case "list":
+		return c.list()
+	case "create":
+		if len(args) < 2 {
+			return fmt.Errorf("missing version to create")
+		}
+		from := ""
+		if len(args) > 2 {
+			from = args[2]
+		}
+		return c.create(args[1], from)
+	case "stable":
+		if len(args) < 2 {
+			return fmt.Errorf("missing version to mark as stable")
+		}
+		return c.stable(args[1])
+	case "delete":
+		if len(args) < 2 {
+			return fmt.Errorf("missing version to delete")
+		}
+		return c.delete(args[1])
+	default:
+		return fmt.Errorf("unknown version command: %s", args[0])
+	}
+}
+
+func (c *ManageVersion) list() error {
+	res, err := c.client.VersionsContext(c.ctx)
+	if err != nil {
+		return err
+	}
+	if c.structured() {
+		list := &versionList{Versions: make([]*versionSummary, 0)}
+		for _, v := range res {
+			list.Versions = append(list.Versions, &versionSummary{
+				Version:    v.Version,
+				Codename:   v.Codename,
+				Stable:     v.IsStable,
+				Beta:       v.IsBeta,
+				Hidden:     v.IsHidden,
+				Deprecated: v.IsDeprecated,
+			})
+		}
+		return c.emit(list)
+	}
+	if c.client.Output != nil {
+		return nil
+	}
+	c.printf("Got %d versions:", len(res))
+	for _, v := range res {
+		flags := ""
+		if v.IsStable {
+			flags += " (stable)"
+		}
+		if v.IsBeta {
+			flags += " (beta)"
+		}
+		if v.IsHidden {
+			flags += " (hidden)"
+		}
+		if v.IsDeprecated {
+			flags += " (deprecated)"
+		}
+		c.printf("- %s : %s%s", v.Version, v.Codename, flags)
+	}
+	return nil
+}
+
+func (c *ManageVersion) find(version string) (*readme.Version, error) {
+	res, err := c.client.VersionsContext(c.ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, v := range res {
+		if version == "" && v.IsStable || version != "" && v.Version == version {
+			return v, nil
+		}
+	}
+	if version == "" {
+		return nil, fmt.Errorf("no stable version found")
+	}
+	return nil, fmt.Errorf("no such version: %s", version)
+}
+
+func (c *ManageVersion) create(version, from string) error {
+	base, err := c.find(from)
+	if err != nil {
+		return err
+	}
+	c.printf("Creating version '%s' from '%s'", version, base.Version)
+	res, err := c.client.CreateVersionContext(c.ctx, base.Version, &readme.Version{
+		Version: version,
+	})
+	if err != nil {
+		return err
+	}
+	c.printf("Version '%s' is created", res.Version)
+	return nil
+}
+
+func (c *ManageVersion) stable(version string) error {
+	ver, err := c.find(version)
+	if err != nil {
+		return err
+	}
+	if ver.IsStable {
+		c.printf("Version '%s' is already stable", version)
+		return nil
+	}
+	cont, err := c.yesOrNo("Are you sure to mark version '%s' as stable?", version)
+	if err != nil {
+		return err
+	}
+	if !cont {
+		c.printf("Version '%s' is not changed", version)
+		return nil
+	}
+	ver.IsStable = true
+	ver.IsHidden = false
+	_, err = c.client.UpdateVersionContext(c.ctx, version, ver)
+	if err != nil {
+		return err
+	}
+	c.printf("Version '%s' is now stable", version)
+	return nil
+}
+
+func (c *ManageVersion) delete(version string) error {
+	meta, err := c.metadata()
+	if err != nil {
+		return err
+	}
+	cont, err := c.confirmDestructive("Are you sure to delete version '%s' from remote?", version)
+	if err != nil {
+		return err
+	}
+	if !cont {
+		c.printf("Version '%s' is not deleted", version)
+		return nil
+	}
+	c.printf("Deleting version from ReadMe: %s", version)
+	err = c.client.DeleteVersionContext(c.ctx, version)
+	if err != nil {
+		return err
+	}
+	root := meta.Root()
+	if root.Versions[version] == nil {
+		return nil
+	}
+	delete(root.Versions, version)
+	return c.writeMetadata(root)
+}
@@ -0,0 +1,320 @@
+package main
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"strings"
+
+	"github.com/cedricshih/readme/api/readme"
+	"github.com/cedricshih/readme/repository"
+	"github.com/sergi/go-diff/diffmatchpatch"
+)
+
+const (
+	conflictLocal  = "<<<<<<< local\n"
+	conflictSep    = "=======\n"
+	conflictRemote = ">>>>>>> remote\n"
+)
+
+type hunk struct {
+	start int
+	end   int
+	lines []string
+}
+
+func hashBody(body string) string {
+	sum := sha1.Sum([]byte(body))
+	return hex.EncodeToString(sum[:])
+}
+
+func hasConflictMarkers(body string) bool {
+	for _, line := range strings.SplitAfter(body, "\n") {
+		if line == conflictLocal || line == conflictSep || line == conflictRemote {
+			return true
+		}
+	}
+	return false
+}
+
+func splitLines(text string) []string {
+	lines := strings.SplitAfter(text, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// linesToRunes encodes each distinct line as a rune so that the diff engine
+// compares whole lines, skipping the surrogate range which does not survive
+// the conversion back to string.
+func linesToRunes(text string, index map[string]rune, lines map[rune]string) []rune {
+	res := make([]rune, 0)
+	for _, line := range splitLines(text) {
+		r, ok := index[line]
+		if !ok {
+			r = rune(len(index) + 1)
+			if r >= 0xd800 {
+				r += 0x800
+			}
+			index[line] = r
+			lines[r] = line
+		}
+		res = append(res, r)
+	}
+	return res
+}
+
+func hunks(base, other string) []*hunk {
+	index := make(map[string]rune)
+	lineArray := make(map[rune]string)
+	baseRunes := linesToRunes(base, index, lineArray)
+	otherRunes := linesToRunes(other, index, lineArray)
+	dmp := diffmatchpatch.New()
+	diffs := dmp.DiffMainRunes(baseRunes, otherRunes, false)
+	res := make([]*hunk, 0)
+	var cur *hunk
+	pos := 0
+	for _, d := range diffs {
+		runes := []rune(d.Text)
+		switch d.Type {
+		case diffmatchpatch.DiffEqual:
+			cur = nil
+			pos += len(runes)
+		case diffmatchpatch.DiffDelete:
+			if cur == nil {
+				cur = &hunk{start: pos, end: pos}
+				res = append(res, cur)
+			}
+			pos += len(runes)
+			cur.end = pos
+		case diffmatchpatch.DiffInsert:
+			if cur == nil {
+				cur = &hunk{start: pos, end: pos}
+				res = append(res, cur)
+			}
+			for _, r := range runes {
+				cur.lines = append(cur.lines, lineArray[r])
+			}
+		}
+	}
+	return res
+}
+
+func applyHunks(base []string, start, end int, hs []*hunk) string {
+	var sb strings.Builder
+	pos := start
+	for _, h := range hs {
+		sb.WriteString(strings.Join(base[pos:h.start], ""))
+		sb.WriteString(strings.Join(h.lines, ""))
+		pos = h.end
+	}
+	sb.WriteString(strings.Join(base[pos:end], ""))
+	return sb.String()
+}
+
+func withNewline(text string) string {
+	if text != "" && !strings.HasSuffix(text, "\n") {
+		return text + "\n"
+	}
+	return text
+}
+
+// mergeText performs a line based three-way merge of local and remote
+// against base, marking overlapping changes with conflict markers.
+func mergeText(base, local, remote string) (string, bool) {
+	lines := splitLines(base)
+	locals := hunks(base, local)
+	remotes := hunks(base, remote)
+	var sb strings.Builder
+	conflict := false
+	pos := 0
+	for len(locals) > 0 || len(remotes) > 0 {
+		var groupLocal, groupRemote []*hunk
+		start, end := -1, -1
+		take := func(hs *[]*hunk, group *[]*hunk) bool {
+			if len(*hs) == 0 {
+				return false
+			}
+			h := (*hs)[0]
+			if start >= 0 && h.start > end {
+				return false
+			}
+			*hs = (*hs)[1:]
+			*group = append(*group, h)
+			if start < 0 {
+				start = h.start
+			}
+			if h.end > end {
+				end = h.end
+			}
+			return true
+		}
+		if len(remotes) == 0 || len(locals) > 0 && locals[0].start <= remotes[0].start {
+			take(&locals, &groupLocal)
+		} else {
+			take(&remotes, &groupRemote)
+		}
+		for take(&locals, &groupLocal) || take(&remotes, &groupRemote) {
+		}
+		sb.WriteString(strings.Join(lines[pos:start], ""))
+		switch {
+		case len(groupRemote) == 0:
+			sb.WriteString(applyHunks(lines, start, end, groupLocal))
+		case len(groupLocal) == 0:
+			sb.WriteString(applyHunks(lines, start, end, groupRemote))
+		default:
+			l := applyHunks(lines, start, end, groupLocal)
+			r := applyHunks(lines, start, end, groupRemote)
+			if l == r {
+				sb.WriteString(l)
+			} else {
+				conflict = true
+				sb.WriteString(conflictLocal)
+				sb.WriteString(withNewline(l))
+				sb.WriteString(conflictSep)
+				sb.WriteString(withNewline(r))
+				sb.WriteString(conflictRemote)
+			}
+		}
+		pos = end
+	}
+	sb.WriteString(strings.Join(lines[pos:], ""))
+	return sb.String(), conflict
+}
+
+func (c *RemoteCommand) mergeField(slug, name, base, local, remote string) (string, error) {
+	if local == base || local == remote {
+		return remote, nil
+	}
+	if remote == base {
+		return local, nil
+	}
+	yes, err := c.yesOrNo("%s of '%s' is changed on both sides, take remote '%s' instead of local '%s'?", name, slug, remote, local)
+	if err != nil {
+		return "", err
+	}
+	if yes {
+		return remote, nil
+	}
+	return local, nil
+}
+
+func (c *RemoteCommand) merge(base, local, remote *readme.Doc) (*readme.Doc, bool, error) {
+	var err error
+	res := &readme.Doc{
+		ID:        remote.ID,
+		Slug:      local.Slug,
+		Category:  remote.Category,
+		Hidden:    local.Hidden,
+		Type:      local.Type,
+		Order:     local.Order,
+		ParentDoc: local.ParentDoc,
+	}
+	res.Title, err = c.mergeField(local.Slug, "Title", base.Title, local.Title, remote.Title)
+	if err != nil {
+		return nil, false, err
+	}
+	res.Excerpt, err = c.mergeField(local.Slug, "Excerpt", base.Excerpt, local.Excerpt, remote.Excerpt)
+	if err != nil {
+		return nil, false, err
+	}
+	// local wins when these are changed on both sides
+	if local.Hidden == base.Hidden {
+		res.Hidden = remote.Hidden
+	}
+	if local.Type == base.Type {
+		res.Type = remote.Type
+	}
+	if local.Order == base.Order {
+		res.Order = remote.Order
+	}
+	if local.ParentDoc == base.ParentDoc {
+		res.ParentDoc = remote.ParentDoc
+	}
+	body, conflict := mergeText(base.Body, local.Body, remote.Body)
+	res.Body = body
+	return res, conflict, nil
+}
+
+func (c *RemoteCommand) mergeDoc(meta *repository.Metadata, cat, catID string, base, local, remote *readme.Doc, push bool) (bool, error) {
+	slug := local.Slug
+	path := c.docFilePath(cat, slug)
+	localChanged := docChanged(base, local)
+	remoteChanged := docChanged(base, remote)
+	switch {
+	case !localChanged && !remoteChanged:
+		c.printf("Doc '%s' is not changed", slug)
+		c.record(meta, cat, slug, actionUnchanged)
+		return false, nil
+	case localChanged && !remoteChanged:
+		if !push {
+			c.printf("Doc '%s' is only changed locally, push it to update remote", slug)
+			c.record(meta, cat, slug, actionKeptLocal)
+			return false, nil
+		}
+		c.diff(remote, local)
+		c.printf("Pushing to ReadMe: %s", path)
+		err := c.client.UpdateDocContext(c.ctx, catID, local)
+		if err != nil {
+			return false, err
+		}
+		c.record(meta, cat, slug, actionPushed)
+		return true, c.saveDoc(meta, cat, catID, local, local)
+	case !localChanged && remoteChanged:
+		c.diff(local, remote)
+		c.printf("Doc '%s' is only changed remotely, updating local copy", slug)
+		c.record(meta, cat, slug, actionPulled)
+		return true, c.saveDoc(meta, cat, catID, remote, remote)
+	}
+	choice, err := c.resolveConflict(slug)
+	if err != nil {
+		return false, err
+	}
+	switch choice {
+	case resolveSkip:
+		c.printf("Doc '%s' is skipped", slug)
+		c.record(meta, cat, slug, actionSkipped)
+		return false, nil
+	case resolveLocal:
+		if !push {
+			c.printf("Keeping local changes of doc '%s', push it to update remote", slug)
+			c.record(meta, cat, slug, actionKeptLocal)
+			return true, c.saveDoc(meta, cat, catID, local, remote)
+		}
+		c.diff(remote, local)
+		c.printf("Pushing to ReadMe: %s", path)
+		err = c.client.UpdateDocContext(c.ctx, catID, local)
+		if err != nil {
+			return false, err
+		}
+		c.record(meta, cat, slug, actionPushed)
+		return true, c.saveDoc(meta, cat, catID, local, local)
+	case resolveRemote:
+		c.diff(local, remote)
+		c.printf("Taking remote changes of doc '%s', updating local copy", slug)
+		c.record(meta, cat, slug, actionPulled)
+		return true, c.saveDoc(meta, cat, catID, remote, remote)
+	}
+	c.printf("Doc '%s' is changed on both sides, merging...", slug)
+	merged, conflict, err := c.merge(base, local, remote)
+	if err != nil {
+		return false, err
+	}
+	if conflict {
+		c.printf("Doc '%s' has conflicts, please resolve them in '%s' and push again", slug, path)
+		c.record(meta, cat, slug, actionConflict)
+		return true, c.saveDoc(meta, cat, catID, merged, remote)
+	}
+	if push && docChanged(remote, merged) {
+		c.diff(remote, merged)
+		c.printf("Pushing merged doc to ReadMe: %s", path)
+		err = c.client.UpdateDocContext(c.ctx, catID, merged)
+		if err != nil {
+			return false, err
+		}
+		c.record(meta, cat, slug, actionMerged)
+		return true, c.saveDoc(meta, cat, catID, merged, merged)
+	}
+	c.record(meta, cat, slug, actionMerged)
+	return true, c.saveDoc(meta, cat, catID, merged, remote)
+}
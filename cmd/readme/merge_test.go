@@ -0,0 +1,89 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestMergeText(t *testing.T) {
+	tests := []struct {
+		name     string
+		base     string
+		local    string
+		remote   string
+		want     string
+		conflict bool
+	}{
+		{
+			name:   "non-overlapping",
+			base:   "a\nb\nc\nd\ne\n",
+			local:  "a\nB\nc\nd\ne\n",
+			remote: "a\nb\nc\nD\ne\n",
+			want:   "a\nB\nc\nD\ne\n",
+		},
+		{
+			name:     "overlapping",
+			base:     "a\nb\nc\nd\n",
+			local:    "a\nb\nlocal\nd\n",
+			remote:   "a\nb\nremote\nd\n",
+			want:     "a\nb\n<<<<<<< local\nlocal\n=======\nremote\n>>>>>>> remote\nd\n",
+			conflict: true,
+		},
+		{
+			name:   "local delete apart from remote append",
+			base:   "a\nb\nc\n",
+			local:  "b\nc\n",
+			remote: "a\nb\nc\nd\n",
+			want:   "b\nc\nd\n",
+		},
+		{
+			// adjacent changes conflict as they do in git
+			name:     "local delete next to remote append",
+			base:     "a\nb\nc\n",
+			local:    "a\nb\n",
+			remote:   "a\nb\nc\nd\n",
+			want:     "a\nb\n<<<<<<< local\n=======\nc\nd\n>>>>>>> remote\n",
+			conflict: true,
+		},
+		{
+			name:   "empty base, local only",
+			base:   "",
+			local:  "a\n",
+			remote: "",
+			want:   "a\n",
+		},
+		{
+			name:     "empty base, both",
+			base:     "",
+			local:    "a\n",
+			remote:   "b\n",
+			want:     "<<<<<<< local\na\n=======\nb\n>>>>>>> remote\n",
+			conflict: true,
+		},
+		{
+			name:   "identical changes",
+			base:   "a\nb\nc\n",
+			local:  "a\nB\nc\nd\n",
+			remote: "a\nB\nc\nd\n",
+			want:   "a\nB\nc\nd\n",
+		},
+		{
+			name:     "no trailing newline",
+			base:     "a\nb",
+			local:    "a\nlocal",
+			remote:   "a\nremote",
+			want:     "a\n<<<<<<< local\nlocal\n=======\nremote\n>>>>>>> remote\n",
+			conflict: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, conflict := mergeText(tt.base, tt.local, tt.remote)
+			if got != tt.want || conflict != tt.conflict {
+				t.Errorf("got %q, conflict %v, want %q, conflict %v", got, conflict, tt.want, tt.conflict)
+			}
+			if conflict != hasConflictMarkers(got) {
+				t.Errorf("conflict %v but markers %v", conflict, hasConflictMarkers(got))
+			}
+		})
+	}
+}
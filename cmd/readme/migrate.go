@@ -0,0 +1,208 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/cedricshih/readme/api/readme"
+	"github.com/cedricshih/readme/repository"
+	"gopkg.in/yaml.v2"
+)
+
+// pairDoc is a doc stored as '<slug>.md' with its metadata in '<slug>.yaml'.
+type pairDoc struct {
+	Category string
+	Title    string
+	Excerpt  string
+	Hidden   bool
+	Body     string `yaml:"-"`
+}
+
+func readPairDoc(dir, slug string) (*pairDoc, error) {
+	bodyFilename := filepath.Join(dir, fmt.Sprintf("%s.md", slug))
+	metaFilename := filepath.Join(dir, fmt.Sprintf("%s.yaml", slug))
+	data, err := ioutil.ReadFile(metaFilename)
+	if err != nil {
+		return nil, err
+	}
+	doc := &pairDoc{}
+	err = yaml.Unmarshal(data, doc)
+	if err != nil {
+		return nil, err
+	}
+	body, err := ioutil.ReadFile(bodyFilename)
+	if err != nil {
+		return nil, err
+	}
+	doc.Body = string(body)
+	return doc, nil
+}
+
+type Migrate struct {
+	*RemoteCommand
+}
+
+func (c *Migrate) Usage(w io.Writer, progname, cmdname string) {
+	fmt.Fprintf(w, "%s %s\n\n", progname, cmdname)
+	fmt.Fprintf(w, "Convert local docs into '<category>/<slug>.md' files with front matter:\n\n")
+	fmt.Fprintf(w, "- '<category>/<slug>.md' files described in metadata.yaml\n")
+	fmt.Fprintf(w, "- '<slug>.md' and '<slug>.yaml' pairs in the doc root\n")
+}
+
+func (c *Migrate) MinArguments() int {
+	return 0
+}
+
+func (c *Migrate) Run(args []string) error {
+	meta, err := c.metadata()
+	if err != nil {
+		return err
+	}
+	root := meta.Root()
+	if root.Format == repository.FormatFrontMatter {
+		c.printf("Docs in '%s' are already in front matter format", c.docRoot)
+		return nil
+	}
+	cont, err := c.yesOrNo("Are you sure to convert docs in '%s' to front matter format?", c.docRoot)
+	if err != nil {
+		return err
+	}
+	if !cont {
+		c.printf("Docs are not converted")
+		return nil
+	}
+	c.frontMatter = true
+	err = c.migrateMetadata(root)
+	if err != nil {
+		return err
+	}
+	err = c.migratePairs(meta)
+	if err != nil {
+		return err
+	}
+	root.Format = repository.FormatFrontMatter
+	return c.writeMetadata(root)
+}
+
+func (c *Migrate) migrateMetadata(root *repository.Metadata) error {
+	version := c.version
+	defer func() {
+		c.version = version
+	}()
+	versions := []string{""}
+	for v := range root.Versions {
+		versions = append(versions, v)
+	}
+	sort.Strings(versions[1:])
+	for _, v := range versions {
+		c.version = v
+		meta := root.Version(v)
+		for _, cat := range meta.CategoryKeys() {
+			for _, slug := range meta.Categories[cat].DocKeys() {
+				docMeta := meta.Categories[cat].Docs[slug]
+				path := c.docFilePath(cat, slug)
+				fm, body, err := readDocFile(path)
+				if err != nil {
+					if os.IsNotExist(err) {
+						continue
+					}
+					return err
+				}
+				if fm != nil {
+					c.printf("Doc '%s' already has front matter: %s", slug, path)
+					continue
+				}
+				err = c.writeLocalDoc(meta, cat, &readme.Doc{
+					Slug:    slug,
+					Title:   docMeta.Title,
+					Excerpt: docMeta.Excerpt,
+					Hidden:  docMeta.Hidden,
+					Type:    docMeta.Type,
+					Order:   docMeta.Order,
+					Body:    body,
+				})
+				if err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func (c *Migrate) migratePairs(meta *repository.Metadata) error {
+	files, err := filepath.Glob(filepath.Join(c.docRoot, "*.yaml"))
+	if err != nil {
+		return err
+	}
+	for _, f := range files {
+		if f == c.metadataFilePath() || filepath.Base(f) == localConfig {
+			continue
+		}
+		slug := strings.TrimSuffix(filepath.Base(f), filepath.Ext(f))
+		body := filepath.Join(c.docRoot, fmt.Sprintf("%s.md", slug))
+		_, err := os.Stat(body)
+		if os.IsNotExist(err) {
+			continue
+		}
+		d, err := readPairDoc(c.docRoot, slug)
+		if err != nil {
+			return err
+		}
+		if d.Category == "" {
+			c.printf("Doc '%s' has no category, skipped: %s", slug, f)
+			continue
+		}
+		cat, catMeta, exist := meta.Doc(slug)
+		if exist != nil {
+			cont, err := c.yesOrNo("Doc '%s' is also in '%s', replace it with '%s'?", slug, c.metadataFilePath(), body)
+			if err != nil {
+				return err
+			}
+			if !cont {
+				c.printf("Doc '%s' is not converted", slug)
+				continue
+			}
+			err = c.remove(c.docFilePath(cat, slug))
+			if err != nil && !os.IsNotExist(err) {
+				return err
+			}
+			delete(catMeta.Docs, slug)
+		}
+		if meta.Categories[d.Category] == nil {
+			cat, err := c.client.CategoryContext(c.ctx, d.Category)
+			if err != nil {
+				return err
+			}
+			meta.AddCategory(d.Category, cat.ID)
+		}
+		meta.Categories[d.Category].Docs[slug] = &repository.Doc{
+			Title:   d.Title,
+			Excerpt: d.Excerpt,
+			Hidden:  d.Hidden,
+		}
+		err = c.writeLocalDoc(meta, d.Category, &readme.Doc{
+			Slug:    slug,
+			Title:   d.Title,
+			Excerpt: d.Excerpt,
+			Hidden:  d.Hidden,
+			Body:    d.Body,
+		})
+		if err != nil {
+			return err
+		}
+		for _, path := range []string{body, f} {
+			c.printf("Removing: %s", path)
+			err = c.remove(path)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,216 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/cedricshih/readme/repository"
+)
+
+type MoveDocument struct {
+	*RemoteCommand
+}
+
+func (c *MoveDocument) Usage(w io.Writer, progname, cmdname string) {
+	fmt.Fprintf(w, "%s %s <slug> <category>\n\n", progname, cmdname)
+	fmt.Fprintf(w, "The doc and its child docs are moved to the top level of the category, both on ReadMe and locally.\n")
+	fmt.Fprintf(w, "Moving the file to another category folder and pushing it does the same.\n\n")
+	fmt.Fprintf(w, "Examples:\n\n")
+	fmt.Fprintf(w, "%s %s quick-start getting-started\n", progname, cmdname)
+}
+
+func (c *MoveDocument) MinArguments() int {
+	return 2
+}
+
+func (c *MoveDocument) HasResult(args []string) bool {
+	return true
+}
+
+func (c *MoveDocument) Run(args []string) error {
+	err := c.run(args)
+	if err != nil {
+		return err
+	}
+	return c.emitActions()
+}
+
+func (c *MoveDocument) run(args []string) error {
+	slug, to := args[0], args[1]
+	meta, err := c.metadata()
+	if err != nil {
+		return err
+	}
+	from, _, docMeta := meta.Doc(slug)
+	if docMeta == nil {
+		return fmt.Errorf("doc '%s' not found in '%s'", slug, c.metadataFilePath())
+	}
+	if from == to {
+		c.printf("Doc '%s' is already in '%s'", slug, to)
+		return nil
+	}
+	cont, err := c.yesOrNo("Are you sure to move doc '%s' from '%s' to '%s'?", slug, from, to)
+	if err != nil {
+		return err
+	}
+	if !cont {
+		c.printf("Doc '%s' is not moved", slug)
+		c.record(meta, from, slug, actionSkipped)
+		return nil
+	}
+	err = c.moveDoc(meta, slug, from, to, "")
+	if err != nil {
+		return c.saveProgress(meta, true, err)
+	}
+	return c.writeMetadata(meta)
+}
+
+// locateDoc searches every category folder for a doc, returning the
+// category it is in and its parents.
+func (c *RemoteCommand) locateDoc(meta *repository.Metadata, slug string) (string, []string, error) {
+	dirs, err := ioutil.ReadDir(c.versionPath())
+	if err != nil {
+		return "", nil, err
+	}
+	root := meta.Root()
+	for _, dir := range dirs {
+		if !dir.IsDir() || strings.HasPrefix(dir.Name(), ".") {
+			continue
+		}
+		if c.version == "" && (root.Versions[dir.Name()] != nil || reservedDirs[dir.Name()]) {
+			continue
+		}
+		_, parents, err := c.repo().FindDoc(dir.Name(), slug)
+		if err == nil {
+			return dir.Name(), parents, nil
+		}
+		if !os.IsNotExist(err) {
+			return "", nil, err
+		}
+	}
+	return "", nil, &os.PathError{Op: "locate", Path: slug, Err: os.ErrNotExist}
+}
+
+// moveDoc moves a doc and its children from one category to another under
+// parent, or to the top level if parent is "", on ReadMe and then locally.
+func (c *RemoteCommand) moveDoc(meta *repository.Metadata, slug, from, to, parent string) error {
+	toID := ""
+	if toMeta := meta.Categories[to]; toMeta != nil {
+		toID = toMeta.ID
+	}
+	if toID == "" {
+		cat, err := c.client.CategoryContext(c.ctx, to)
+		if err != nil {
+			return err
+		}
+		toID = cat.ID
+	}
+	parentID := ""
+	if parent != "" {
+		parentMeta := meta.Categories[to].Docs[parent]
+		if parentMeta == nil || parentMeta.ID == "" {
+			return fmt.Errorf("parent doc '%s' of '%s' is not on ReadMe yet, please push it first", parent, slug)
+		}
+		parentID = parentMeta.ID
+	}
+	remote, err := c.client.DocContext(c.ctx, slug)
+	if err != nil {
+		return err
+	}
+	remote.ParentDoc = parentID
+	c.printf("Moving on ReadMe: '%s' from '%s' to '%s'", slug, from, to)
+	err = c.client.UpdateDocContext(c.ctx, toID, remote)
+	if err != nil {
+		return err
+	}
+	err = c.moveLocalDoc(meta, slug, from, to, toID, parent)
+	if err != nil {
+		return err
+	}
+	docMeta := meta.Categories[to].Docs[slug]
+	if docMeta.Base != nil {
+		docMeta.Base.ParentDoc = parentID
+	}
+	c.record(meta, to, slug, actionMoved).From = from
+	for _, child := range meta.Categories[from].DocKeys() {
+		if meta.Categories[from].Docs[child].Parent != slug {
+			continue
+		}
+		err = c.moveDoc(meta, child, from, to, slug)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// moveLocalDoc moves a doc from one category to another in metadata, and
+// its file under the folder of parent if it is still in the old category.
+func (c *RemoteCommand) moveLocalDoc(meta *repository.Metadata, slug, from, to, toID, parent string) error {
+	fromMeta := meta.Categories[from]
+	docMeta := fromMeta.Docs[slug]
+	delete(fromMeta.Docs, slug)
+	docMeta.Parent = parent
+	meta.AddCategory(to, toID).Docs[slug] = docMeta
+	path, _, err := c.repo().FindDoc(from, slug)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	newPath := c.repo().DocPath(to, meta.Parents(to, slug), slug)
+	c.printf("Moving doc: %s => %s", path, newPath)
+	err = c.mkdirAll(filepath.Dir(newPath))
+	if err != nil {
+		return err
+	}
+	err = c.rename(path, newPath)
+	if err != nil {
+		return err
+	}
+	c.removeEmptyDirs(filepath.Dir(path), c.categoryPath(from))
+	return c.updatePlacement(to, slug)
+}
+
+// followMove moves a doc in metadata to the category folder its file has
+// been moved to, on ReadMe too if it is there, and returns the category
+// the doc is in.
+func (c *RemoteCommand) followMove(meta *repository.Metadata, cat, slug string) (string, error) {
+	_, _, err := c.repo().FindDoc(cat, slug)
+	if err == nil || !os.IsNotExist(err) {
+		return cat, err
+	}
+	to, parents, err := c.locateDoc(meta, slug)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return cat, nil
+		}
+		return "", err
+	}
+	parent := ""
+	if len(parents) > 0 {
+		parent = parents[len(parents)-1]
+	}
+	err = c.checkPlacement(to, slug)
+	if err != nil {
+		return "", err
+	}
+	docMeta := meta.Categories[cat].Docs[slug]
+	if docMeta.ID == "" && docMeta.Base == nil {
+		// not on ReadMe yet
+		return to, c.moveLocalDoc(meta, slug, cat, to, "", parent)
+	}
+	cont, err := c.yesOrNo("Doc '%s' is moved from '%s' to '%s', move it on ReadMe too?", slug, cat, to)
+	if err != nil {
+		return "", err
+	}
+	if !cont {
+		return "", fmt.Errorf("doc '%s' is not moved, move it back to '%s' to push it", slug, c.categoryPath(cat))
+	}
+	return to, c.moveDoc(meta, slug, cat, to, parent)
+}
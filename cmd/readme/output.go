@@ -0,0 +1,199 @@
+package main
+
+import (
+	"encoding/json"
+
+	"github.com/cedricshih/readme/api/readme"
+	"github.com/cedricshih/readme/repository"
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	formatTable = "table"
+	formatJSON  = "json"
+	formatYAML  = "yaml"
+)
+
+// Actions taken on a doc by push and pull.
+const (
+	actionCreated   = "created"
+	actionPushed    = "pushed"
+	actionPulled    = "pulled"
+	actionMerged    = "merged"
+	actionConflict  = "conflict"
+	actionDeleted   = "deleted"
+	actionMoved     = "moved"
+	actionRenamed   = "renamed"
+	actionKeptLocal = "kept-local"
+	actionUnchanged = "unchanged"
+	actionSkipped   = "skipped"
+)
+
+// docSummary is a doc in the result of listing docs.
+type docSummary struct {
+	Slug      string `json:"slug" yaml:"slug"`
+	Title     string `json:"title" yaml:"title"`
+	Hidden    bool   `json:"hidden" yaml:"hidden"`
+	Type      string `json:"type" yaml:"type"`
+	Order     int    `json:"order" yaml:"order"`
+	ParentDoc string `json:"parentDoc,omitempty" yaml:"parentDoc,omitempty"`
+}
+
+type docList struct {
+	Category string        `json:"category" yaml:"category"`
+	Docs     []*docSummary `json:"docs" yaml:"docs"`
+}
+
+// docDetail is the result of getting a doc.
+type docDetail struct {
+	docSummary `yaml:",inline"`
+	ID         string `json:"id" yaml:"id"`
+	Category   string `json:"category" yaml:"category"`
+	Excerpt    string `json:"excerpt" yaml:"excerpt"`
+	Body       string `json:"body" yaml:"body"`
+}
+
+type categorySummary struct {
+	ID    string `json:"id" yaml:"id"`
+	Slug  string `json:"slug" yaml:"slug"`
+	Title string `json:"title" yaml:"title"`
+	Type  string `json:"type" yaml:"type"`
+	Order int    `json:"order" yaml:"order"`
+}
+
+type categoryList struct {
+	Categories []*categorySummary `json:"categories" yaml:"categories"`
+}
+
+type versionSummary struct {
+	Version    string `json:"version" yaml:"version"`
+	Codename   string `json:"codename" yaml:"codename"`
+	Stable     bool   `json:"stable" yaml:"stable"`
+	Beta       bool   `json:"beta" yaml:"beta"`
+	Hidden     bool   `json:"hidden" yaml:"hidden"`
+	Deprecated bool   `json:"deprecated" yaml:"deprecated"`
+}
+
+type versionList struct {
+	Versions []*versionSummary `json:"versions" yaml:"versions"`
+}
+
+type changelogSummary struct {
+	Slug      string `json:"slug" yaml:"slug"`
+	Title     string `json:"title" yaml:"title"`
+	Type      string `json:"type" yaml:"type"`
+	Hidden    bool   `json:"hidden" yaml:"hidden"`
+	CreatedAt string `json:"createdAt" yaml:"createdAt"`
+}
+
+type changelogList struct {
+	Changelogs []*changelogSummary `json:"changelogs" yaml:"changelogs"`
+}
+
+type customPageSummary struct {
+	Slug     string `json:"slug" yaml:"slug"`
+	Title    string `json:"title" yaml:"title"`
+	Hidden   bool   `json:"hidden" yaml:"hidden"`
+	HTMLMode bool   `json:"htmlMode" yaml:"htmlMode"`
+}
+
+type customPageList struct {
+	CustomPages []*customPageSummary `json:"customPages" yaml:"customPages"`
+}
+
+type specSummary struct {
+	ID       string `json:"id" yaml:"id"`
+	Title    string `json:"title" yaml:"title"`
+	Type     string `json:"type" yaml:"type"`
+	Version  string `json:"version" yaml:"version"`
+	Category string `json:"category,omitempty" yaml:"category,omitempty"`
+}
+
+type specList struct {
+	Specs []*specSummary `json:"specs" yaml:"specs"`
+}
+
+// docAction is what a command did to a doc.
+type docAction struct {
+	Category string `json:"category" yaml:"category"`
+	Slug     string `json:"slug" yaml:"slug"`
+	Action   string `json:"action" yaml:"action"`
+	// From is the category a doc is moved from, or the slug it is renamed
+	// from.
+	From string `json:"from,omitempty" yaml:"from,omitempty"`
+	URL  string `json:"url,omitempty" yaml:"url,omitempty"`
+}
+
+type docActions struct {
+	Docs []*docAction `json:"docs" yaml:"docs"`
+}
+
+func newCategorySummary(cat *readme.Category) *categorySummary {
+	return &categorySummary{
+		ID:    cat.ID,
+		Slug:  cat.Slug,
+		Title: cat.Title,
+		Type:  cat.Type,
+		Order: cat.Order,
+	}
+}
+
+func newDocSummary(doc *readme.Doc) *docSummary {
+	return &docSummary{
+		Slug:      doc.Slug,
+		Title:     doc.Title,
+		Hidden:    doc.Hidden,
+		Type:      doc.Type,
+		Order:     doc.Order,
+		ParentDoc: doc.ParentDoc,
+	}
+}
+
+// structured tells if results are emitted as JSON or YAML documents, in
+// which case messages are printed to stderr instead.
+func (c *RemoteCommand) structured() bool {
+	return c.format == formatJSON || c.format == formatYAML
+}
+
+// emit writes a result document in the chosen format.
+func (c *RemoteCommand) emit(v interface{}) error {
+	switch c.format {
+	case formatJSON:
+		enc := json.NewEncoder(c.result)
+		enc.SetIndent("", "  ")
+		return enc.Encode(v)
+	case formatYAML:
+		data, err := yaml.Marshal(v)
+		if err != nil {
+			return err
+		}
+		_, err = c.result.Write(data)
+		return err
+	}
+	return nil
+}
+
+// record notes what a command did to a doc for emitActions.
+func (c *RemoteCommand) record(meta *repository.Metadata, cat, slug, action string) *docAction {
+	a := &docAction{
+		Category: cat,
+		Slug:     slug,
+		Action:   action,
+	}
+	if action != actionSkipped {
+		a.URL = c.docURL(meta, slug)
+	}
+	c.actions = append(c.actions, a)
+	return a
+}
+
+func (c *RemoteCommand) emitActions() error {
+	if !c.structured() {
+		return nil
+	}
+	res := &docActions{Docs: c.actions}
+	if res.Docs == nil {
+		res.Docs = make([]*docAction, 0)
+	}
+	return c.emit(res)
+}
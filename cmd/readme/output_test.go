@@ -0,0 +1,99 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/cedricshih/readme/api/readme"
+	"gopkg.in/yaml.v2"
+)
+
+func TestOutputCategories(t *testing.T) {
+	srv, c := newTestCommand(t)
+	srv.AddCategory("", readme.Category{Title: "Guides"})
+	srv.AddCategory("", readme.Category{Title: "Reference", Type: readme.CategoryTypeReference})
+	out := &bytes.Buffer{}
+	c.result = out
+	c.format = formatJSON
+	err := (&ListCategories{c}).Run(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	list := &categoryList{}
+	err = json.Unmarshal(out.Bytes(), list)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list.Categories) != 2 || list.Categories[1].Slug != "reference" || list.Categories[1].ID == "" {
+		t.Errorf("got %s", out)
+	}
+
+	out.Reset()
+	c.format = formatYAML
+	err = (&GetCategory{c}).Run([]string{"guides"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cat := &categorySummary{}
+	err = yaml.Unmarshal(out.Bytes(), cat)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cat.Slug != "guides" || cat.Title != "Guides" {
+		t.Errorf("got %s", out)
+	}
+}
+
+func TestOutputMove(t *testing.T) {
+	srv, c := newTestCommand(t)
+	srv.AddCategory("", readme.Category{Title: "Guides"})
+	srv.AddCategory("", readme.Category{Title: "Reference"})
+	srv.AddDoc("", "guides", readme.Doc{Slug: "intro", Title: "Intro", Body: "hello\n"})
+	err := (&Synchronize{c}).Run(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.actions = nil
+	out := &bytes.Buffer{}
+	c.result = out
+	c.format = formatJSON
+	err = (&MoveDocument{c}).Run([]string{"intro", "reference"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	res := &docActions{}
+	err = json.Unmarshal(out.Bytes(), res)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Docs) != 1 || *res.Docs[0] != (docAction{Category: "reference", Slug: "intro", Action: actionMoved, From: "guides", URL: res.Docs[0].URL}) {
+		t.Errorf("got %s", out)
+	}
+}
+
+func TestHasResult(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		want bool
+	}{
+		{"categories", nil, true},
+		{"docs", []string{"guides"}, true},
+		{"mv", []string{"intro", "reference"}, true},
+		{"version", []string{"list"}, true},
+		{"version", []string{"delete", "1.0"}, false},
+		{"changelog", []string{"list"}, true},
+		{"changelog", []string{"push", "release"}, false},
+		{"custompage", []string{"list"}, true},
+		{"spec", []string{"list"}, true},
+		{"category", []string{"create", "Guides"}, false},
+		{"migrate", nil, false},
+	}
+	for _, tt := range tests {
+		rc, ok := commands.Lookup(tt.name).Command.(ResultCommand)
+		if got := ok && rc.HasResult(tt.args); got != tt.want {
+			t.Errorf("%s %v: got %v, want %v", tt.name, tt.args, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,179 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Resolutions of a doc changed on both sides.
+const (
+	resolveLocal  = "local"
+	resolveRemote = "remote"
+	resolveMerge  = "merge"
+	resolveSkip   = "skip"
+)
+
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// readLine reads an answer from the input, which is buffered once so that
+// piped answers to successive prompts are not lost.
+func (c *RemoteCommand) readLine() (string, error) {
+	if c.ctx.Err() != nil {
+		return "", c.ctx.Err()
+	}
+	if c.reader == nil {
+		c.reader = bufio.NewReader(c.input)
+	}
+	text, err := c.reader.ReadString('\n')
+	if err == io.EOF && text != "" {
+		err = nil
+	}
+	return strings.TrimSpace(text), err
+}
+
+// ask prints question and reads answers until parse accepts one. Invalid
+// answers are asked again on a terminal and fail otherwise, since nobody is
+// there to correct them.
+func (c *RemoteCommand) ask(question string, parse func(text string) error) error {
+	if c.noInput {
+		return fmt.Errorf("%s: an answer is required but -no-input is set", question)
+	}
+	for {
+		c.printf("%s", question)
+		text, err := c.readLine()
+		if err == io.EOF {
+			return fmt.Errorf("%s: no answer before the end of input", question)
+		}
+		if err != nil {
+			return err
+		}
+		err = parse(text)
+		if err == nil {
+			return nil
+		}
+		if !c.interactive {
+			return fmt.Errorf("%s: %s", question, err.Error())
+		}
+		c.printf("%s", err.Error())
+	}
+}
+
+// yesOrNo asks a question answered by 'yes' by default or with -y.
+func (c *RemoteCommand) yesOrNo(format string, args ...interface{}) (bool, error) {
+	return c.confirm(true, format, args...)
+}
+
+// confirmDestructive asks about deleting or overwriting content, answered
+// by 'no' by default so that an accidental Enter loses nothing, or 'yes'
+// with -y.
+func (c *RemoteCommand) confirmDestructive(format string, args ...interface{}) (bool, error) {
+	return c.confirm(false, format, args...)
+}
+
+func (c *RemoteCommand) confirm(def bool, format string, args ...interface{}) (bool, error) {
+	if c.ctx.Err() != nil {
+		return false, c.ctx.Err()
+	}
+	if c.allYes {
+		return true, nil
+	}
+	hint := "(y/N)"
+	if def {
+		hint = "(Y/n)"
+	}
+	yes := def
+	err := c.ask(fmt.Sprintf(format, args...)+" "+hint, func(text string) error {
+		switch strings.ToLower(text) {
+		case "":
+			yes = def
+		case "y", "yes":
+			yes = true
+		case "n", "no":
+			yes = false
+		default:
+			return fmt.Errorf("invalid answer '%s', please answer y or n", text)
+		}
+		return nil
+	})
+	return yes, err
+}
+
+// choose asks to select one of items, returning -1 if the answer is empty.
+func (c *RemoteCommand) choose(items []string, format string, args ...interface{}) (int, string, error) {
+	if c.ctx.Err() != nil {
+		return -1, "", c.ctx.Err()
+	}
+	c.printf(format, args...)
+	for i, it := range items {
+		c.printf("%d:\t%s", i+1, it)
+	}
+	index := -1
+	err := c.ask(fmt.Sprintf("Enter 1-%d, or nothing to cancel:", len(items)), func(text string) error {
+		if text == "" {
+			return nil
+		}
+		num, err := strconv.Atoi(text)
+		if err != nil || num <= 0 || num > len(items) {
+			return fmt.Errorf("invalid selection '%s'", text)
+		}
+		index = num - 1
+		return nil
+	})
+	if err != nil || index < 0 {
+		return -1, "", err
+	}
+	return index, items[index], nil
+}
+
+// resolveConflict asks how to resolve a doc changed on both sides, unless
+// an earlier answer applies to all of them. Merging is the default.
+func (c *RemoteCommand) resolveConflict(slug string) (string, error) {
+	if c.ctx.Err() != nil {
+		return "", c.ctx.Err()
+	}
+	if c.resolution != "" {
+		return c.resolution, nil
+	}
+	if c.allYes {
+		return resolveMerge, nil
+	}
+	res := resolveMerge
+	question := fmt.Sprintf("Doc '%s' is changed on both sides, keep [l]ocal, take [r]emote, [m]erge or [s]kip? Append 'all' to apply to the rest (l/r/M/s)", slug)
+	err := c.ask(question, func(text string) error {
+		fields := strings.Fields(strings.ToLower(text))
+		all := len(fields) > 0 && fields[len(fields)-1] == "all"
+		if all {
+			fields = fields[:len(fields)-1]
+		}
+		if len(fields) > 1 {
+			return fmt.Errorf("invalid answer '%s'", text)
+		}
+		res = resolveMerge
+		if len(fields) == 1 {
+			switch fields[0] {
+			case "l", resolveLocal:
+				res = resolveLocal
+			case "r", resolveRemote:
+				res = resolveRemote
+			case "m", resolveMerge:
+				res = resolveMerge
+			case "s", resolveSkip:
+				res = resolveSkip
+			default:
+				return fmt.Errorf("invalid answer '%s', please answer l, r, m or s", text)
+			}
+		}
+		if all {
+			c.resolution = res
+		}
+		return nil
+	})
+	return res, err
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestConfirm(t *testing.T) {
+	tests := []struct {
+		name        string
+		input       string
+		destructive bool
+		allYes      bool
+		want        bool
+		wantErr     bool
+	}{
+		{"empty", "\n", false, false, true, false},
+		{"empty destructive", "\n", true, false, false, false},
+		{"yes destructive", "y\n", true, false, true, false},
+		{"no", "no\n", false, false, false, false},
+		{"all yes destructive", "", true, true, true, false},
+		{"invalid", "maybe\n", true, false, false, true},
+		{"end of input", "", true, false, false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, c := newTestCommand(t)
+			c.allYes = tt.allYes
+			c.input = strings.NewReader(tt.input)
+			confirm := c.yesOrNo
+			if tt.destructive {
+				confirm = c.confirmDestructive
+			}
+			got, err := confirm("Delete doc '%s'?", "intro")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("got %v, want error %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
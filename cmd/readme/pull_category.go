@@ -2,6 +2,7 @@ package main
 
 import (
 	"fmt"
+	"io"
 
 	"github.com/cedricshih/readme/api/readme"
 )
@@ -10,11 +11,31 @@ type PullCategory struct {
 	*RemoteCommand
 }
 
+func (c *PullCategory) Usage(w io.Writer, progname, cmdname string) {
+	fmt.Fprintf(w, "%s %s [slug]\n\n", progname, cmdname)
+	fmt.Fprintf(w, "Pull a doc into its category folder, or choose a category and pull one or all of its docs.\n\n")
+	fmt.Fprintf(w, "Examples:\n\n")
+	fmt.Fprintf(w, "%s %s\n", progname, cmdname)
+	fmt.Fprintf(w, "%s %s quick-start\n", progname, cmdname)
+}
+
 func (c *PullCategory) MinArguments() int {
 	return 0
 }
 
+func (c *PullCategory) HasResult(args []string) bool {
+	return true
+}
+
 func (c *PullCategory) Run(args []string) error {
+	err := c.run(args)
+	if err != nil {
+		return err
+	}
+	return c.emitActions()
+}
+
+func (c *PullCategory) run(args []string) error {
 	meta, err := c.metadata()
 	if err != nil {
 		return err
@@ -24,16 +45,16 @@ func (c *PullCategory) Run(args []string) error {
 	docSlug := ""
 	if len(args) > 0 {
 		docSlug = args[0]
-		doc, err = c.client.Doc(docSlug)
+		doc, err = c.client.DocContext(c.ctx, docSlug)
 		if err != nil {
 			return err
 		}
-		cats, err := c.client.Categories()
+		cats, err := c.client.CategoriesContext(c.ctx)
 		if err != nil {
 			return err
 		}
 		for _, cc := range cats {
-			docs, err := c.client.Docs(cc.Slug)
+			docs, err := c.client.DocsContext(c.ctx, cc.Slug)
 			if err != nil {
 				return err
 			}
@@ -59,7 +80,7 @@ func (c *PullCategory) Run(args []string) error {
 		if catSlug == "" {
 			return nil
 		}
-		cat, err = c.client.Category(catSlug)
+		cat, err = c.client.CategoryContext(c.ctx, catSlug)
 		if err != nil {
 			return err
 		}
@@ -71,16 +92,10 @@ func (c *PullCategory) Run(args []string) error {
 			return nil
 		}
 		if docSlug == selectionAll {
-			changed, err := c.pullCategory(meta, cat)
-			if err != nil {
-				return err
-			}
-			if changed {
-				return c.writeMetadata(meta)
-			}
-			return nil
+			changed, err := c.pullCategories(meta, []*readme.Category{cat})
+			return c.saveProgress(meta, changed, err)
 		}
-		doc, err = c.client.Doc(docSlug)
+		doc, err = c.client.DocContext(c.ctx, docSlug)
 		if err != nil {
 			return err
 		}
@@ -5,8 +5,6 @@ import (
 	"io"
 	"path/filepath"
 	"strings"
-
-	"github.com/sergi/go-diff/diffmatchpatch"
 )
 
 type PullDocument struct {
@@ -24,72 +22,40 @@ func (c *PullDocument) MinArguments() int {
 	return 1
 }
 
+func (c *PullDocument) HasResult(args []string) bool {
+	return true
+}
+
 func (c *PullDocument) Run(args []string) error {
 	slug := args[0]
 	slug = filepath.Base(slug)
 	slug = strings.TrimSuffix(slug, filepath.Ext(slug))
-	return c.run(slug)
+	err := c.run(slug)
+	if err != nil {
+		return err
+	}
+	return c.emitActions()
 }
 
 func (c *PullDocument) run(slug string) error {
-	remote, err := c.client.Doc(slug)
+	remote, err := c.client.DocContext(c.ctx, slug)
 	if err != nil {
 		return err
 	}
-	cat, err := c.client.CategoryByID(remote.Category)
+	cat, err := c.client.CategoryByIDContext(c.ctx, remote.Category)
 	if err != nil {
 		return err
 	}
-	doc := RemoteDoc(cat.Slug, remote)
-	old, err := LocalDoc(c.docRoot, slug)
-	if err == nil {
-		diff := c.diffDoc(slug, old, doc)
-		if diff {
-			cont, err := c.yesOrNo("Are you sure to pull '%s' and overwrite local changes?", slug)
-			if err != nil {
-				return err
-			}
-			if !cont {
-				c.printf("Doc '%s' is not pulled", slug)
-				return nil
-			}
-		} else {
-			c.printf("Doc '%s' is alignmed with the remote one", slug)
-			return nil
-		}
-	}
-	err = doc.Save(c.docRoot, slug)
+	meta, err := c.metadata()
 	if err != nil {
 		return err
 	}
-	return nil
-}
-
-func (c *RemoteCommand) diffDoc(slug string, old, new *doc) bool {
-	c.printf("Checking '%s' for difference...", slug)
-	diff := false
-	if old.Category != new.Category {
-		c.printf("Category: %s => %s", old.Category, new.Category)
-		diff = true
-	}
-	if old.Title != new.Title {
-		c.printf("Title: %s => %s", old.Title, new.Title)
-		diff = true
-	}
-	if old.Excerpt != new.Excerpt {
-		c.printf("Excerpt: %s => %s", old.Excerpt, new.Excerpt)
-		diff = true
-	}
-	if old.Hidden != new.Hidden {
-		c.printf("Hidden: %v => %v", old.Hidden, new.Hidden)
-		diff = true
+	changed, err := c.pullDoc(meta, cat, remote)
+	if err != nil {
+		return err
 	}
-	if old.Body != new.Body {
-		c.printf("Body:")
-		dmp := diffmatchpatch.New()
-		diffs := dmp.DiffMain(old.Body, new.Body, false)
-		fmt.Println(dmp.DiffPrettyText(diffs))
-		diff = true
+	if changed {
+		return c.writeMetadata(meta)
 	}
-	return diff
+	return nil
 }
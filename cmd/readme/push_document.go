@@ -2,20 +2,40 @@ package main
 
 import (
 	"fmt"
-	"io/ioutil"
+	"io"
 
 	"github.com/cedricshih/readme/api/readme"
+	"github.com/cedricshih/readme/repository"
 )
 
 type PushDocument struct {
 	*RemoteCommand
 }
 
+func (c *PushDocument) Usage(w io.Writer, progname, cmdname string) {
+	fmt.Fprintf(w, "%s %s [slug]\n\n", progname, cmdname)
+	fmt.Fprintf(w, "The doc is created on ReadMe if it is listed in metadata but not found on remote.\n\n")
+	fmt.Fprintf(w, "Examples:\n\n")
+	fmt.Fprintf(w, "%s %s quick-start\n", progname, cmdname)
+}
+
 func (c *PushDocument) MinArguments() int {
 	return 0
 }
 
+func (c *PushDocument) HasResult(args []string) bool {
+	return true
+}
+
 func (c *PushDocument) Run(args []string) error {
+	err := c.run(args)
+	if err != nil {
+		return err
+	}
+	return c.emitActions()
+}
+
+func (c *PushDocument) run(args []string) error {
 	doc := ""
 	if len(args) > 0 {
 		doc = args[0]
@@ -41,28 +61,65 @@ func (c *PushDocument) Run(args []string) error {
 	}
 	cat, catMeta, docMeta := meta.Doc(doc)
 	if docMeta == nil {
-		c.printf("Doc '%s' not found in '%s', please create the doc on ReadMe dashboard and do a 'pull'.", doc, c.metadataFilePath())
+		c.printf("Doc '%s' not found in '%s', please add it under its category and push again.", doc, c.metadataFilePath())
 		return nil
 	}
+	moved, err := c.followMove(meta, cat, doc)
+	if err != nil {
+		return err
+	}
+	if moved != cat {
+		cat, catMeta = moved, meta.Categories[moved]
+		err = c.writeMetadata(meta)
+		if err != nil {
+			return err
+		}
+	}
 	path := c.docFilePath(cat, doc)
-	body, err := ioutil.ReadFile(path)
+	new, err := c.localDoc(meta, cat, doc, docMeta)
 	if err != nil {
 		return err
 	}
-	old, err := c.client.Doc(doc)
+	old, err := c.client.DocContext(c.ctx, doc)
 	if err != nil {
+		if readme.IsNotFound(err) {
+			return c.create(meta, cat, catMeta, new)
+		}
 		return err
 	}
-	new := &readme.Doc{
-		Slug:    doc,
-		Title:   docMeta.Title,
-		Excerpt: docMeta.Excerpt,
-		Hidden:  docMeta.Hidden,
-		Body:    string(body),
+	if docMeta.ID == "" {
+		// tracked before the hierarchy was, keep it as is on ReadMe
+		// unless the doc is nested locally
+		new.ID = old.ID
+		new.Type = old.Type
+		new.Order = old.Order
+		if new.ParentDoc == "" {
+			new.ParentDoc = old.ParentDoc
+		}
+	}
+	if hasConflictMarkers(new.Body) {
+		return fmt.Errorf("doc '%s' has unresolved conflicts: %s", doc, path)
+	}
+	base := c.baseDoc(doc, docMeta)
+	if base != nil {
+		changed, err := c.mergeDoc(meta, cat, catMeta.ID, base, new, old, true)
+		if err != nil {
+			return err
+		}
+		if !changed {
+			return nil
+		}
+		err = c.writeMetadata(meta)
+		if err != nil {
+			return err
+		}
+		c.printf("Doc '%s' is synchronized with: %s", doc, c.docURL(meta, doc))
+		return nil
 	}
 	diff := c.diff(old, new)
 	if !diff {
 		c.printf("Doc '%s' is unchanged", doc)
+		c.record(meta, cat, doc, actionUnchanged)
 		return nil
 	}
 	cont, err := c.yesOrNo("Are you sure to push doc '%s' to remote?", doc)
@@ -71,14 +128,88 @@ func (c *PushDocument) Run(args []string) error {
 	}
 	if !cont {
 		c.printf("Doc '%s' is not pushed", doc)
+		c.record(meta, cat, doc, actionSkipped)
 		return nil
 	}
 	c.printf("Pushing to ReadMe: %s", path)
-	err = c.client.UpdateDoc(catMeta.ID, new)
+	err = c.client.UpdateDocContext(c.ctx, catMeta.ID, new)
+	if err != nil {
+		return err
+	}
+	c.record(meta, cat, doc, actionPushed)
+	err = c.saveDoc(meta, cat, catMeta.ID, new, new)
 	if err != nil {
 		return err
 	}
-	u := fmt.Sprintf("%s/docs/%s", meta.BaseURL, doc)
+	err = c.writeMetadata(meta)
+	if err != nil {
+		return err
+	}
+	u := c.docURL(meta, doc)
 	c.printf("Doc '%s' is pushed to: %s", doc, u)
 	return nil
 }
+
+func (c *PushDocument) create(meta *repository.Metadata, cat string, catMeta *repository.Category, doc *readme.Doc) error {
+	cont, err := c.yesOrNo("Doc '%s' does not exist on remote, are you sure to create it?", doc.Slug)
+	if err != nil {
+		return err
+	}
+	if !cont {
+		c.printf("Doc '%s' is not created", doc.Slug)
+		c.record(meta, cat, doc.Slug, actionSkipped)
+		return nil
+	}
+	if catMeta.ID == "" {
+		remoteCat, err := c.client.CategoryContext(c.ctx, cat)
+		if err != nil {
+			return err
+		}
+		catMeta.ID = remoteCat.ID
+	}
+	path := c.docFilePath(cat, doc.Slug)
+	c.printf("Creating on ReadMe: %s", path)
+	res, err := c.client.CreateDocContext(c.ctx, catMeta.ID, doc)
+	if err != nil {
+		return err
+	}
+	parents := repository.DocParents(c.categoryPath(cat), path)
+	if res.Slug != doc.Slug {
+		// ReadMe may derive a different slug from the title
+		newPath := c.repo().DocPath(cat, parents, res.Slug)
+		c.printf("Doc '%s' is created as '%s', renaming: %s => %s", doc.Slug, res.Slug, path, newPath)
+		err = c.rename(path, newPath)
+		if err != nil {
+			return err
+		}
+		delete(catMeta.Docs, doc.Slug)
+	}
+	parent := ""
+	if len(parents) > 0 {
+		parent = parents[len(parents)-1]
+	}
+	docMeta := &repository.Doc{
+		ID:      res.ID,
+		Title:   res.Title,
+		Excerpt: res.Excerpt,
+		Hidden:  res.Hidden,
+		Type:    res.Type,
+		Order:   res.Order,
+		Parent:  parent,
+	}
+	catMeta.Docs[res.Slug] = docMeta
+	c.record(meta, cat, res.Slug, actionCreated)
+	synced := *res
+	synced.Body = doc.Body
+	err = c.writeBase(docMeta, &synced)
+	if err != nil {
+		return err
+	}
+	err = c.writeMetadata(meta)
+	if err != nil {
+		return err
+	}
+	u := c.docURL(meta, res.Slug)
+	c.printf("Doc '%s' is created at: %s", res.Slug, u)
+	return nil
+}
@@ -2,190 +2,406 @@ package main
 
 import (
 	"bufio"
+	"context"
 	"fmt"
 	"io"
 	"io/ioutil"
 	"os"
-	"path/filepath"
-	"strconv"
 	"strings"
 
 	"github.com/cedricshih/readme/api/readme"
+	"github.com/cedricshih/readme/repository"
 	"github.com/sergi/go-diff/diffmatchpatch"
-	"gopkg.in/yaml.v2"
 )
 
 const (
 	selectionAll = "(all)"
 )
 
+// reservedDirs are folders in the doc root which are not categories.
+var reservedDirs = map[string]bool{
+	"changelog":   true,
+	"custompages": true,
+}
+
 type RemoteCommand struct {
+	ctx     context.Context
 	output  io.Writer
 	input   io.Reader
 	client  *readme.Client
 	docRoot string
+	version string
 	allYes  bool
+	// jobs is the number of concurrent fetches from ReadMe
+	jobs int
+	// frontMatter is set when docs carry their metadata in front matter
+	frontMatter bool
+	// dryRun skips local writes, recording them to plan instead
+	dryRun bool
+	plan   localPlan
+	// interactive is set when answers come from a terminal, noInput when
+	// prompts must fail instead of waiting for answers
+	interactive bool
+	noInput     bool
+	reader      *bufio.Reader
+	// resolution applies to every doc changed on both sides once chosen
+	resolution string
+	// format is how results are written to result, see structured
+	format  string
+	result  io.Writer
+	actions []*docAction
+	// log receives diagnostics, unlike output which is for the user
+	log readme.Logger
 }
 
 func (t *RemoteCommand) printf(format string, args ...interface{}) {
 	fmt.Fprintf(t.output, format+"\n", args...)
 }
 
-func (c *RemoteCommand) pullCategory(meta *Metadata, cat *readme.Category) (bool, error) {
-	changed := false
-	docs, err := c.client.Docs(cat.Slug)
+func (c *RemoteCommand) logf(level readme.Level, format string, args ...interface{}) {
+	if c.log != nil {
+		c.log.Logf(level, format, args...)
+	}
+}
+
+// pullCategories fetches the docs of cats concurrently, then pulls them one
+// by one so that prompts and writes happen in a deterministic order.
+func (c *RemoteCommand) pullCategories(meta *repository.Metadata, cats []*readme.Category) (bool, error) {
+	lists, err := c.fetchDocs(cats)
 	if err != nil {
 		return false, err
 	}
-	for _, doc := range docs {
-		doc, err = c.client.Doc(doc.Slug)
-		if err != nil {
-			return false, err
-		}
-		chg, err := c.pullDoc(meta, cat, doc)
-		if err != nil {
-			return false, err
+	changed := false
+	for i, cat := range cats {
+		for _, doc := range lists[i] {
+			chg, err := c.pullDoc(meta, cat, doc)
+			changed = changed || chg
+			if err != nil {
+				return changed, err
+			}
 		}
-		changed = changed || chg
 	}
 	return changed, nil
 }
 
-func (c *RemoteCommand) pullDoc(meta *Metadata, cat *readme.Category, doc *readme.Doc) (bool, error) {
-	_, _, exist := meta.Doc(doc.Slug)
-	if exist != nil {
-		path := c.docFilePath(cat.Slug, doc.Slug)
-		body, err := ioutil.ReadFile(path)
+func (c *RemoteCommand) pullDoc(meta *repository.Metadata, cat *readme.Category, doc *readme.Doc) (bool, error) {
+	existCat, _, exist := meta.Doc(doc.Slug)
+	if exist != nil && existCat != cat.Slug {
+		parent, err := c.parentSlug(meta, cat.Slug, doc.ParentDoc)
 		if err != nil {
 			return false, err
 		}
-		old := &readme.Doc{
-			Slug:    doc.Slug,
-			Title:   exist.Title,
-			Excerpt: exist.Excerpt,
-			Hidden:  exist.Hidden,
-			Body:    string(body),
-		}
-		diff := c.diff(old, doc)
-		if !diff {
-			c.printf("Doc '%s' is not changed", doc.Slug)
-			return false, nil
-		}
-		cont, err := c.yesOrNo("Are you sure to pull '%s' and overwrite local changes?", doc.Slug)
+		c.printf("Doc '%s' is moved from '%s' to '%s' on ReadMe", doc.Slug, existCat, cat.Slug)
+		err = c.moveLocalDoc(meta, doc.Slug, existCat, cat.Slug, cat.ID, parent)
 		if err != nil {
 			return false, err
 		}
-		if !cont {
-			c.printf("Doc '%s' is not pulled", doc.Slug)
-			return false, nil
-		}
+		_, err = c.pullDocContent(meta, cat, doc, exist)
+		return true, err
 	}
-	if meta.Categories[cat.Slug] == nil {
-		meta.Categories[cat.Slug] = &Category{
-			ID:   cat.ID,
-			Docs: make(map[string]*Doc),
+	return c.pullDocContent(meta, cat, doc, exist)
+}
+
+func (c *RemoteCommand) pullDocContent(meta *repository.Metadata, cat *readme.Category, doc *readme.Doc, exist *repository.Doc) (bool, error) {
+	if exist != nil {
+		old, err := c.localDoc(meta, cat.Slug, doc.Slug, exist)
+		if err == nil {
+			base := c.baseDoc(doc.Slug, exist)
+			if base != nil {
+				return c.mergeDoc(meta, cat.Slug, cat.ID, base, old, doc, false)
+			}
+			diff := c.diff(old, doc)
+			if !diff {
+				c.printf("Doc '%s' is not changed", doc.Slug)
+				c.record(meta, cat.Slug, doc.Slug, actionUnchanged)
+				return false, nil
+			}
+			cont, err := c.confirmDestructive("Are you sure to pull '%s' and overwrite local changes?", doc.Slug)
+			if err != nil {
+				return false, err
+			}
+			if !cont {
+				c.printf("Doc '%s' is not pulled", doc.Slug)
+				c.record(meta, cat.Slug, doc.Slug, actionSkipped)
+				return false, nil
+			}
+		} else if !os.IsNotExist(err) {
+			return false, err
 		}
 	}
-	meta.Categories[cat.Slug].Docs[doc.Slug] = &Doc{
+	err := c.saveDoc(meta, cat.Slug, cat.ID, doc, doc)
+	if err != nil {
+		return false, err
+	}
+	c.record(meta, cat.Slug, doc.Slug, actionPulled)
+	return true, nil
+}
+
+// saveDoc writes doc to the local copy and records synced as the base of
+// the next three-way merge.
+func (c *RemoteCommand) saveDoc(meta *repository.Metadata, cat, catID string, doc, synced *readme.Doc) error {
+	parent, err := c.parentSlug(meta, cat, doc.ParentDoc)
+	if err != nil {
+		return err
+	}
+	docMeta := &repository.Doc{
+		ID:      doc.ID,
 		Title:   doc.Title,
 		Excerpt: doc.Excerpt,
 		Hidden:  doc.Hidden,
+		Type:    doc.Type,
+		Order:   doc.Order,
+		Parent:  parent,
 	}
-	path := c.docFilePath(cat.Slug, doc.Slug)
-	err := os.MkdirAll(c.categoryPath(cat.Slug), os.ModePerm)
+	meta.AddCategory(cat, catID).Docs[doc.Slug] = docMeta
+	err = c.writeLocalDoc(meta, cat, doc)
 	if err != nil {
-		return false, err
+		return err
+	}
+	return c.writeBase(docMeta, synced)
+}
+
+func (c *RemoteCommand) baseDoc(slug string, docMeta *repository.Doc) *readme.Doc {
+	if docMeta.Base == nil {
+		return nil
 	}
-	c.printf("Writing doc: %s", path)
-	err = ioutil.WriteFile(path, []byte(doc.Body), os.ModePerm)
+	body, err := ioutil.ReadFile(c.baseFilePath(slug))
 	if err != nil {
-		return false, err
+		return nil
+	}
+	if hashBody(string(body)) != docMeta.Base.Hash {
+		return nil
+	}
+	return &readme.Doc{
+		ID:        docMeta.ID,
+		Slug:      slug,
+		Title:     docMeta.Base.Title,
+		Excerpt:   docMeta.Base.Excerpt,
+		Hidden:    docMeta.Base.Hidden,
+		Type:      docMeta.Base.Type,
+		Order:     docMeta.Base.Order,
+		ParentDoc: docMeta.Base.ParentDoc,
+		Body:      string(body),
 	}
-	return true, nil
 }
 
-func (c *RemoteCommand) metadata() (*Metadata, error) {
-	prj, err := c.client.Project()
+// parentSlug returns the slug of the parent doc with id, looking it up in
+// the category on ReadMe if it is not tracked.
+func (c *RemoteCommand) parentSlug(meta *repository.Metadata, cat, id string) (string, error) {
+	if id == "" {
+		return "", nil
+	}
+	slug, _ := meta.DocByID(id)
+	if slug != "" {
+		return slug, nil
+	}
+	docs, err := c.client.CategoryDocsContext(c.ctx, cat)
 	if err != nil {
-		return nil, err
+		return "", err
 	}
-	meta := &Metadata{}
-	path := c.metadataFilePath()
-	data, err := ioutil.ReadFile(path)
+	for _, d := range docs {
+		if d.ID == id {
+			return d.Slug, nil
+		}
+	}
+	return "", fmt.Errorf("parent doc '%s' not found in category '%s'", id, cat)
+}
+
+func (c *RemoteCommand) writeBase(docMeta *repository.Doc, doc *readme.Doc) error {
+	err := c.writeFile(c.baseFilePath(doc.Slug), []byte(doc.Body))
 	if err != nil {
-		if os.IsNotExist(err) {
-			c.printf("Creating new metadata...")
-			meta.SubDomain = prj.SubDomain
-			meta.Categories = make(map[string]*Category)
-		} else {
-			return nil, err
+		return err
+	}
+	docMeta.Base = &repository.Base{
+		Title:     doc.Title,
+		Excerpt:   doc.Excerpt,
+		Hidden:    doc.Hidden,
+		Type:      doc.Type,
+		Order:     doc.Order,
+		ParentDoc: doc.ParentDoc,
+		Hash:      hashBody(doc.Body),
+	}
+	return nil
+}
+
+func (c *RemoteCommand) pruneDocs(meta *repository.Metadata) (bool, error) {
+	changed := false
+	err := meta.ForEachDoc(func(cat string, _ *repository.Category, slug string, _ *repository.Doc) error {
+		_, err := os.Stat(c.docFilePath(cat, slug))
+		if err == nil {
+			return nil
+		}
+		if !os.IsNotExist(err) {
+			return err
 		}
-	} else {
-		err = yaml.Unmarshal(data, meta)
+		to, _, err := c.locateDoc(meta, slug)
+		if err == nil {
+			c.printf("Doc '%s' is moved to '%s' locally, push it to move it on ReadMe", slug, to)
+			return nil
+		}
+		if !os.IsNotExist(err) {
+			return err
+		}
+		cont, err := c.confirmDestructive("Doc '%s' is deleted locally, are you sure to delete it on remote?", slug)
 		if err != nil {
-			return nil, err
+			return err
 		}
-		if prj.SubDomain != meta.SubDomain {
-			return nil, fmt.Errorf("the API key is from another project '%s' not '%s'", prj.SubDomain, meta.SubDomain)
+		if !cont {
+			c.printf("Doc '%s' is not deleted", slug)
+			c.record(meta, cat, slug, actionSkipped)
+			return nil
 		}
+		err = c.deleteDoc(meta, slug)
+		if err != nil {
+			return err
+		}
+		c.record(meta, cat, slug, actionDeleted)
+		changed = true
+		return nil
+	})
+	return changed, err
+}
+
+func (c *RemoteCommand) deleteDoc(meta *repository.Metadata, slug string) error {
+	c.printf("Deleting from ReadMe: %s", slug)
+	err := c.client.DeleteDocContext(c.ctx, slug)
+	if err != nil && !readme.IsNotFound(err) {
+		return err
 	}
-	meta.BaseURL = prj.BaseUrl
-	return meta, nil
+	_, cat, exist := meta.Doc(slug)
+	if exist != nil {
+		delete(cat.Docs, slug)
+	}
+	err = c.remove(c.baseFilePath(slug))
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+func (c *RemoteCommand) repo() *repository.Repository {
+	return repository.New(c.docRoot, c.version)
 }
 
-func (c *RemoteCommand) writeMetadata(meta *Metadata) error {
-	data, err := yaml.Marshal(meta)
+func (c *RemoteCommand) metadata() (*repository.Metadata, error) {
+	prj, err := c.client.ProjectContext(c.ctx)
 	if err != nil {
-		return err
+		return nil, err
 	}
+	meta, err := c.repo().Load()
+	if err != nil {
+		if !os.IsNotExist(err) {
+			return nil, err
+		}
+		c.printf("Creating new metadata...")
+		meta = repository.NewMetadata(prj.SubDomain)
+	} else if prj.SubDomain != meta.SubDomain {
+		return nil, fmt.Errorf("the API key is from another project '%s' not '%s'", prj.SubDomain, meta.SubDomain)
+	}
+	meta.BaseURL = prj.BaseUrl
+	c.frontMatter = meta.Format == repository.FormatFrontMatter
+	return meta.Version(c.version), nil
+}
+
+// saveProgress writes metadata changed before err interrupted a command, so
+// that files already written are tracked, and returns err.
+func (c *RemoteCommand) saveProgress(meta *repository.Metadata, changed bool, err error) error {
+	if changed {
+		werr := c.writeMetadata(meta)
+		if werr != nil {
+			return werr
+		}
+	}
+	return err
+}
+
+func (c *RemoteCommand) writeMetadata(meta *repository.Metadata) error {
 	path := c.metadataFilePath()
 	c.printf("Writing metadata: %s", path)
-	err = ioutil.WriteFile(path, data, os.ModePerm)
-	if err != nil {
-		return err
+	if c.dryRun {
+		c.plan.add("write", path)
+		return nil
 	}
-	return nil
+	return c.repo().Save(meta)
 }
 
 func (c *RemoteCommand) metadataFilePath() string {
-	return filepath.Join(c.docRoot, "metadata.yaml")
+	return c.repo().MetadataPath()
+}
+
+func (c *RemoteCommand) versionPath() string {
+	return c.repo().VersionPath()
+}
+
+func (c *RemoteCommand) baseFilePath(doc string) string {
+	return c.repo().BasePath(doc)
 }
 
 func (c *RemoteCommand) categoryPath(cat string) string {
-	return filepath.Join(c.docRoot, cat)
+	return c.repo().CategoryPath(cat)
 }
 
+// docFilePath returns where a doc is in the category folder, or where it
+// would be at the top level if it is not found.
 func (c *RemoteCommand) docFilePath(cat, doc string) string {
-	return filepath.Join(c.docRoot, cat, fmt.Sprintf("%s.md", doc))
+	path, _, err := c.repo().FindDoc(cat, doc)
+	if err != nil {
+		return c.repo().DocPath(cat, nil, doc)
+	}
+	return path
 }
 
-func (c *RemoteCommand) diff(old, new *readme.Doc) bool {
-	c.printf("Checking '%s' for difference...", old.Slug)
-	diff := false
-	if old.Title != new.Title {
-		c.printf("Title: %s => %s", old.Title, new.Title)
-		diff = true
-	}
-	if old.Excerpt != new.Excerpt {
-		c.printf("Excerpt: %s => %s", old.Excerpt, new.Excerpt)
-		diff = true
+func (c *RemoteCommand) docURL(meta *repository.Metadata, doc string) string {
+	if c.version != "" {
+		return fmt.Sprintf("%s/v%s/docs/%s", meta.BaseURL, strings.TrimPrefix(c.version, "v"), doc)
 	}
-	if old.Hidden != new.Hidden {
-		c.printf("Hidden: %v => %v", old.Hidden, new.Hidden)
+	return fmt.Sprintf("%s/docs/%s", meta.BaseURL, doc)
+}
+
+// compareDocs reports whether old and new differ in any field synced with
+// ReadMe, calling changed for each field that does unless it is nil. Sync,
+// push and status all compare docs through it so they agree on changes.
+func compareDocs(old, new *readme.Doc, changed func(field string, old, new interface{})) bool {
+	diff := false
+	check := func(field string, old, new interface{}) {
+		if old == new {
+			return
+		}
 		diff = true
+		if changed != nil {
+			changed(field, old, new)
+		}
 	}
-	if old.Body != new.Body {
+	check("Title", old.Title, new.Title)
+	check("Excerpt", old.Excerpt, new.Excerpt)
+	check("Hidden", old.Hidden, new.Hidden)
+	check("Type", old.Type, new.Type)
+	check("Order", old.Order, new.Order)
+	check("Parent", old.ParentDoc, new.ParentDoc)
+	check("Body", old.Body, new.Body)
+	return diff
+}
+
+func docChanged(old, new *readme.Doc) bool {
+	return compareDocs(old, new, nil)
+}
+
+func (c *RemoteCommand) diff(old, new *readme.Doc) bool {
+	c.printf("Checking '%s' for difference...", old.Slug)
+	return compareDocs(old, new, func(field string, from, to interface{}) {
+		if field != "Body" {
+			c.printf("%s: %v => %v", field, from, to)
+			return
+		}
 		c.printf("Body:")
 		dmp := diffmatchpatch.New()
 		diffs := dmp.DiffMain(old.Body, new.Body, false)
-		fmt.Println(dmp.DiffPrettyText(diffs))
-		diff = true
-	}
-	return diff
+		c.printf("%s", dmp.DiffPrettyText(diffs))
+	})
 }
 
 func (c *RemoteCommand) chooseCategory(all bool) (string, error) {
-	res, err := c.client.Categories()
+	res, err := c.client.CategoriesContext(c.ctx)
 	if err != nil {
 		return "", err
 	}
@@ -204,7 +420,7 @@ func (c *RemoteCommand) chooseCategory(all bool) (string, error) {
 }
 
 func (c *RemoteCommand) chooseDoc(category string, all bool) (string, error) {
-	res, err := c.client.Docs(category)
+	res, err := c.client.DocsContext(c.ctx, category)
 	if err != nil {
 		return "", err
 	}
@@ -221,50 +437,3 @@ func (c *RemoteCommand) chooseDoc(category string, all bool) (string, error) {
 	}
 	return doc, nil
 }
-
-func (c *RemoteCommand) choose(items []string, format string, args ...interface{}) (int, string, error) {
-	c.prompt(items, format, args...)
-	return c.receiveSelection(items)
-}
-
-func (c *RemoteCommand) prompt(items []string, format string, args ...interface{}) {
-	c.printf(format, args...)
-	for i, it := range items {
-		c.printf("%d:\t%s", i+1, it)
-	}
-}
-
-func (c *RemoteCommand) receiveSelection(items []string) (int, string, error) {
-	reader := bufio.NewReader(c.input)
-	text, err := reader.ReadString('\n')
-	if err != nil {
-		return -1, "", err
-	}
-	text = strings.TrimSpace(text)
-	num, err := strconv.ParseInt(text, 10, 32)
-	if err != nil {
-		return -1, "", nil
-	}
-	if num <= 0 || int(num) > len(items) {
-		return -1, "", nil
-	}
-	return int(num) - 1, items[num-1], nil
-}
-
-func (c *RemoteCommand) yesOrNo(format string, args ...interface{}) (bool, error) {
-	if c.allYes {
-		return true, nil
-	}
-	c.printf(format+" (Y/n)", args...)
-	reader := bufio.NewReader(c.input)
-	b, err := reader.ReadByte()
-	if err != nil {
-		return false, err
-	}
-	switch b {
-	case 'Y':
-		return true, nil
-	default:
-		return false, nil
-	}
-}
@@ -0,0 +1,195 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/cedricshih/readme/repository"
+)
+
+// docLinkPattern matches internal links to docs, e.g. [Setup](doc:setup#install).
+var docLinkPattern = regexp.MustCompile(`\bdoc:([A-Za-z0-9_-]+)`)
+
+type RenameDocument struct {
+	*RemoteCommand
+}
+
+func (c *RenameDocument) Usage(w io.Writer, progname, cmdname string) {
+	fmt.Fprintf(w, "%s %s <old-slug> <new-slug>\n\n", progname, cmdname)
+	fmt.Fprintf(w, "The slug is changed on ReadMe, the file and its child docs folder are renamed,\n")
+	fmt.Fprintf(w, "and links like 'doc:<old-slug>' in local Markdown are rewritten to be pushed.\n\n")
+	fmt.Fprintf(w, "Examples:\n\n")
+	fmt.Fprintf(w, "%s %s getting-started quick-start\n", progname, cmdname)
+}
+
+func (c *RenameDocument) MinArguments() int {
+	return 2
+}
+
+func (c *RenameDocument) HasResult(args []string) bool {
+	return true
+}
+
+func (c *RenameDocument) Run(args []string) error {
+	err := c.run(args)
+	if err != nil {
+		return err
+	}
+	return c.emitActions()
+}
+
+func (c *RenameDocument) run(args []string) error {
+	old, slug := args[0], args[1]
+	meta, err := c.metadata()
+	if err != nil {
+		return err
+	}
+	cat, catMeta, docMeta := meta.Doc(old)
+	if docMeta == nil {
+		return fmt.Errorf("doc '%s' not found in '%s'", old, c.metadataFilePath())
+	}
+	if _, _, exist := meta.Doc(slug); exist != nil {
+		return fmt.Errorf("doc '%s' already exists", slug)
+	}
+	cont, err := c.yesOrNo("Are you sure to rename doc '%s' to '%s'?", old, slug)
+	if err != nil {
+		return err
+	}
+	if !cont {
+		c.printf("Doc '%s' is not renamed", old)
+		c.record(meta, cat, old, actionSkipped)
+		return nil
+	}
+	if docMeta.ID != "" || docMeta.Base != nil {
+		remote, err := c.client.DocContext(c.ctx, old)
+		if err != nil {
+			return err
+		}
+		c.printf("Renaming on ReadMe: %s => %s", old, slug)
+		res, err := c.client.RenameDocContext(c.ctx, catMeta.ID, remote, slug)
+		if err != nil {
+			return err
+		}
+		if res.Slug != "" && res.Slug != slug {
+			return fmt.Errorf("doc '%s' is renamed to '%s' on ReadMe instead of '%s', please pull it", old, res.Slug, slug)
+		}
+	}
+	err = c.renameLocalDoc(meta, cat, old, slug)
+	if err != nil {
+		return c.saveProgress(meta, true, err)
+	}
+	c.record(meta, cat, slug, actionRenamed).From = old
+	err = c.writeMetadata(meta)
+	if err != nil {
+		return err
+	}
+	files, err := c.rewriteDocLinks(meta, old, slug)
+	if err != nil {
+		return err
+	}
+	if len(files) > 0 {
+		c.printf("Links to '%s' are rewritten in %d files, please push the docs among them", old, len(files))
+	}
+	c.printf("Doc '%s' is renamed to: %s", old, c.docURL(meta, slug))
+	return nil
+}
+
+// renameLocalDoc renames the file, child docs folder, base and metadata of
+// a doc.
+func (c *RemoteCommand) renameLocalDoc(meta *repository.Metadata, cat, old, slug string) error {
+	catMeta := meta.Categories[cat]
+	docMeta := catMeta.Docs[old]
+	delete(catMeta.Docs, old)
+	catMeta.Docs[slug] = docMeta
+	for _, child := range catMeta.Docs {
+		if child.Parent == old {
+			child.Parent = slug
+		}
+	}
+	path, _, err := c.repo().FindDoc(cat, old)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	renames := [][2]string{
+		{c.baseFilePath(old), c.baseFilePath(slug)},
+	}
+	if path != "" {
+		dir := filepath.Dir(path)
+		renames = append(renames,
+			[2]string{path, filepath.Join(dir, fmt.Sprintf("%s.md", slug))},
+			[2]string{filepath.Join(dir, old), filepath.Join(dir, slug)},
+		)
+	}
+	for _, r := range renames {
+		err = c.rename(r[0], r[1])
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		c.printf("Renaming: %s => %s", r[0], r[1])
+	}
+	for _, child := range catMeta.DocKeys() {
+		if catMeta.Docs[child].Parent != slug {
+			continue
+		}
+		err = c.updatePlacement(cat, child)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// rewriteDocLinks rewrites links to doc old in Markdown files of the
+// current version and returns the files changed.
+func (c *RemoteCommand) rewriteDocLinks(meta *repository.Metadata, old, slug string) ([]string, error) {
+	root := meta.Root()
+	files := make([]string, 0)
+	err := filepath.Walk(c.versionPath(), func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if info.IsDir() {
+			if path == c.versionPath() {
+				return nil
+			}
+			if strings.HasPrefix(info.Name(), ".") {
+				return filepath.SkipDir
+			}
+			if c.version == "" && filepath.Dir(path) == c.versionPath() && root.Versions[info.Name()] != nil {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if filepath.Ext(path) != ".md" || strings.HasPrefix(info.Name(), ".") {
+			return nil
+		}
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		text := docLinkPattern.ReplaceAllStringFunc(string(data), func(link string) string {
+			if link[len("doc:"):] == old {
+				return "doc:" + slug
+			}
+			return link
+		})
+		if text == string(data) {
+			return nil
+		}
+		c.printf("Rewriting links to '%s': %s", old, path)
+		files = append(files, path)
+		return c.writeFile(path, []byte(text))
+	})
+	return files, err
+}
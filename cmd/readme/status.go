@@ -0,0 +1,279 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/cedricshih/readme/api/readme"
+	"github.com/cedricshih/readme/repository"
+)
+
+const (
+	statusUnchanged        = "unchanged"
+	statusModified         = "modified"
+	statusModifiedLocally  = "modified-locally"
+	statusModifiedRemotely = "modified-remotely"
+	statusModifiedBoth     = "modified-both"
+	statusNewLocal         = "new-local"
+	statusNewRemote        = "new-remote"
+	statusDeletedLocally   = "deleted-locally"
+	statusDeletedRemotely  = "deleted-remotely"
+	statusUntracked        = "untracked"
+)
+
+type docStatus struct {
+	State    string `json:"state" yaml:"state"`
+	Category string `json:"category" yaml:"category"`
+	Slug     string `json:"slug" yaml:"slug"`
+}
+
+type statusResult struct {
+	Docs    []*docStatus   `json:"docs" yaml:"docs"`
+	Summary map[string]int `json:"summary" yaml:"summary"`
+}
+
+type Status struct {
+	*RemoteCommand
+	porcelain bool
+	exitCode  bool
+}
+
+func (c *Status) Usage(w io.Writer, progname, cmdname string) {
+	fmt.Fprintf(w, "%s %s [-porcelain] [-exit-code]\n\n", progname, cmdname)
+	fmt.Fprintf(w, "States: %s\n", strings.Join([]string{
+		statusUnchanged, statusModified, statusModifiedLocally, statusModifiedRemotely,
+		statusModifiedBoth, statusNewLocal, statusNewRemote, statusDeletedLocally,
+		statusDeletedRemotely, statusUntracked,
+	}, ", "))
+}
+
+func (c *Status) Flags(fs *flag.FlagSet) {
+	fs.BoolVar(&c.porcelain, "porcelain", c.porcelain, "Print '<state> <category> <slug>' per line without summary")
+	fs.BoolVar(&c.exitCode, "exit-code", c.exitCode, "Fail if any doc is out of sync with ReadMe")
+}
+
+func (c *Status) MinArguments() int {
+	return 0
+}
+
+func (c *Status) HasResult(args []string) bool {
+	return true
+}
+
+func (c *Status) Run(args []string) error {
+	meta, err := c.metadata()
+	if err != nil {
+		return err
+	}
+	res, err := c.status(meta)
+	if err != nil {
+		return err
+	}
+	if c.structured() {
+		summary := make(map[string]int)
+		for _, s := range res {
+			summary[s.State]++
+		}
+		err = c.emit(&statusResult{Docs: res, Summary: summary})
+		if err != nil {
+			return err
+		}
+	} else if c.porcelain {
+		for _, s := range res {
+			c.printf("%s %s %s", s.State, s.Category, s.Slug)
+		}
+	} else {
+		c.printTable(res)
+	}
+	if !c.exitCode {
+		return nil
+	}
+	drift := 0
+	for _, s := range res {
+		if s.State != statusUnchanged {
+			drift++
+		}
+	}
+	if drift > 0 {
+		return fmt.Errorf("%d docs are out of sync with ReadMe", drift)
+	}
+	return nil
+}
+
+func (c *Status) printTable(res []*docStatus) {
+	w := tabwriter.NewWriter(c.output, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(w, "STATE\tCATEGORY\tSLUG\n")
+	counts := make(map[string]int)
+	states := make([]string, 0)
+	for _, s := range res {
+		fmt.Fprintf(w, "%s\t%s\t%s\n", strings.ReplaceAll(s.State, "-", " "), s.Category, s.Slug)
+		if counts[s.State] == 0 {
+			states = append(states, s.State)
+		}
+		counts[s.State]++
+	}
+	w.Flush()
+	sort.Strings(states)
+	summary := make([]string, 0)
+	for _, state := range states {
+		summary = append(summary, fmt.Sprintf("%d %s", counts[state], strings.ReplaceAll(state, "-", " ")))
+	}
+	c.printf("")
+	c.printf("%d docs: %s", len(res), strings.Join(summary, ", "))
+}
+
+func (c *Status) status(meta *repository.Metadata) ([]*docStatus, error) {
+	res := make([]*docStatus, 0)
+	tracked := make(map[string]bool)
+	for _, cat := range meta.CategoryKeys() {
+		catMeta := meta.Categories[cat]
+		for _, slug := range catMeta.DocKeys() {
+			tracked[slug] = true
+			state, err := c.docStatus(meta, cat, slug, catMeta.Docs[slug])
+			if err != nil {
+				return nil, err
+			}
+			res = append(res, &docStatus{
+				State:    state,
+				Category: cat,
+				Slug:     slug,
+			})
+		}
+	}
+	untracked, err := c.untracked(meta, tracked)
+	if err != nil {
+		return nil, err
+	}
+	res = append(res, untracked...)
+	cats, err := c.client.CategoriesContext(c.ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, cat := range cats {
+		docs, err := c.client.CategoryDocsContext(c.ctx, cat.Slug)
+		if err != nil {
+			return nil, err
+		}
+		for _, d := range docs {
+			if tracked[d.Slug] {
+				continue
+			}
+			tracked[d.Slug] = true
+			res = append(res, &docStatus{
+				State:    statusNewRemote,
+				Category: cat.Slug,
+				Slug:     d.Slug,
+			})
+		}
+	}
+	return res, nil
+}
+
+func (c *Status) docStatus(meta *repository.Metadata, cat, slug string, docMeta *repository.Doc) (string, error) {
+	local, err := c.localDoc(meta, cat, slug, docMeta)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return statusDeletedLocally, nil
+		}
+		return "", err
+	}
+	base := c.baseDoc(slug, docMeta)
+	remote, err := c.client.DocContext(c.ctx, slug)
+	if err != nil {
+		if !readme.IsNotFound(err) {
+			return "", err
+		}
+		if docMeta.Base != nil {
+			return statusDeletedRemotely, nil
+		}
+		return statusNewLocal, nil
+	}
+	return syncState(base, local, remote), nil
+}
+
+func syncState(base, local, remote *readme.Doc) string {
+	if base == nil {
+		if docChanged(local, remote) {
+			return statusModified
+		}
+		return statusUnchanged
+	}
+	localChanged := docChanged(base, local)
+	remoteChanged := docChanged(base, remote)
+	switch {
+	case localChanged && remoteChanged:
+		return statusModifiedBoth
+	case localChanged:
+		return statusModifiedLocally
+	case remoteChanged:
+		return statusModifiedRemotely
+	default:
+		return statusUnchanged
+	}
+}
+
+func (c *Status) untracked(meta *repository.Metadata, tracked map[string]bool) ([]*docStatus, error) {
+	res := make([]*docStatus, 0)
+	dirs, err := ioutil.ReadDir(c.versionPath())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return res, nil
+		}
+		return nil, err
+	}
+	root := meta.Root()
+	for _, dir := range dirs {
+		if !dir.IsDir() || strings.HasPrefix(dir.Name(), ".") {
+			continue
+		}
+		if c.version == "" && (root.Versions[dir.Name()] != nil || reservedDirs[dir.Name()]) {
+			continue
+		}
+		files, err := docFiles(c.categoryPath(dir.Name()))
+		if err != nil {
+			return nil, err
+		}
+		for _, f := range files {
+			slug := strings.TrimSuffix(filepath.Base(f), filepath.Ext(f))
+			if tracked[slug] {
+				continue
+			}
+			tracked[slug] = true
+			res = append(res, &docStatus{
+				State:    statusUntracked,
+				Category: dir.Name(),
+				Slug:     slug,
+			})
+		}
+	}
+	return res, nil
+}
+
+// docFiles returns the Markdown files in a category folder and the folders
+// of parent docs nested in it.
+func docFiles(root string) ([]string, error) {
+	res := make([]string, 0)
+	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			if path != root && strings.HasPrefix(info.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if filepath.Ext(path) == ".md" && !strings.HasPrefix(info.Name(), ".") {
+			res = append(res, path)
+		}
+		return nil
+	})
+	return res, err
+}
@@ -1,29 +1,52 @@
 package main
 
+import (
+	"fmt"
+	"io"
+)
+
 type Synchronize struct {
 	*RemoteCommand
 }
 
+func (c *Synchronize) Usage(w io.Writer, progname, cmdname string) {
+	fmt.Fprintf(w, "%s %s\n\n", progname, cmdname)
+	fmt.Fprintf(w, "Pull all categories and docs, and delete remote docs whose local files are deleted.\n")
+}
+
 func (c *Synchronize) MinArguments() int {
 	return 0
 }
 
+func (c *Synchronize) HasResult(args []string) bool {
+	return true
+}
+
 func (c *Synchronize) Run(args []string) error {
-	meta, err := c.metadata()
+	err := c.run(args)
 	if err != nil {
 		return err
 	}
-	cats, err := c.client.Categories()
+	return c.emitActions()
+}
+
+func (c *Synchronize) run(args []string) error {
+	meta, err := c.metadata()
 	if err != nil {
 		return err
 	}
-	changed := false
-	for _, cat := range cats {
-		chg, err := c.pullCategory(meta, cat)
-		if err != nil {
-			return err
-		}
-		changed = changed || chg
+	changed, err := c.pruneDocs(meta)
+	if err != nil {
+		return c.saveProgress(meta, changed, err)
+	}
+	cats, err := c.client.CategoriesContext(c.ctx)
+	if err != nil {
+		return c.saveProgress(meta, changed, err)
+	}
+	chg, err := c.pullCategories(meta, cats)
+	changed = changed || chg
+	if err != nil {
+		return c.saveProgress(meta, changed, err)
 	}
 	if changed {
 		return c.writeMetadata(meta)
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/cedricshih/readme/api/readme"
+	"github.com/cedricshih/readme/api/readme/readmetest"
+)
+
+func newTestCommand(t *testing.T) (*readmetest.Server, *RemoteCommand) {
+	srv := readmetest.NewServer("rdme_test")
+	ts := httptest.NewServer(srv)
+	t.Cleanup(ts.Close)
+	client := readmetest.NewClient(ts, "rdme_test")
+	client.Logger = nil
+	return srv, &RemoteCommand{
+		ctx:     context.Background(),
+		output:  &bytes.Buffer{},
+		input:   strings.NewReader(""),
+		client:  client,
+		docRoot: t.TempDir(),
+		jobs:    2,
+		allYes:  true,
+	}
+}
+
+func docStates(t *testing.T, c *RemoteCommand) map[string]string {
+	meta, err := c.metadata()
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := (&Status{RemoteCommand: c}).status(meta)
+	if err != nil {
+		t.Fatal(err)
+	}
+	states := make(map[string]string)
+	for _, s := range res {
+		states[s.Slug] = s.State
+	}
+	return states
+}
+
+func TestSyncPushStatus(t *testing.T) {
+	srv, c := newTestCommand(t)
+	srv.AddCategory("", readme.Category{Title: "Guides"})
+	intro := srv.AddDoc("", "guides", readme.Doc{Slug: "intro", Title: "Intro", Body: "hello\n"})
+	srv.AddDoc("", "guides", readme.Doc{Slug: "setup", Title: "Setup", Body: "install\n", ParentDoc: intro.ID})
+
+	err := (&Synchronize{c}).Run(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(c.docRoot, "guides", "intro.md")
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "hello") {
+		t.Fatalf("got %q", data)
+	}
+	_, err = ioutil.ReadFile(filepath.Join(c.docRoot, "guides", "intro", "setup.md"))
+	if err != nil {
+		t.Fatalf("child doc is not nested: %v", err)
+	}
+	want := map[string]string{"intro": statusUnchanged, "setup": statusUnchanged}
+	if got := docStates(t, c); !equalStates(got, want) {
+		t.Fatalf("after sync got %v, want %v", got, want)
+	}
+
+	err = ioutil.WriteFile(path, bytes.Replace(data, []byte("hello"), []byte("hello, world"), 1), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want["intro"] = statusModifiedLocally
+	if got := docStates(t, c); !equalStates(got, want) {
+		t.Fatalf("after edit got %v, want %v", got, want)
+	}
+
+	err = (&PushDocument{c}).Run([]string{"intro"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if body := srv.Doc("", "intro").Body; body != "hello, world\n" {
+		t.Fatalf("pushed body %q", body)
+	}
+	want["intro"] = statusUnchanged
+	if got := docStates(t, c); !equalStates(got, want) {
+		t.Fatalf("after push got %v, want %v", got, want)
+	}
+
+	setup := srv.Doc("", "setup")
+	setup.Body = "install it\n"
+	err = c.client.UpdateDoc(srv.Category("", "guides").ID, setup)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want["setup"] = statusModifiedRemotely
+	if got := docStates(t, c); !equalStates(got, want) {
+		t.Fatalf("after remote edit got %v, want %v", got, want)
+	}
+	err = (&Synchronize{c}).Run(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want["setup"] = statusUnchanged
+	if got := docStates(t, c); !equalStates(got, want) {
+		t.Fatalf("after second sync got %v, want %v", got, want)
+	}
+}
+
+func equalStates(got, want map[string]string) bool {
+	if len(got) != len(want) {
+		return false
+	}
+	for slug, state := range want {
+		if got[slug] != state {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,202 @@
+package repository
+
+import (
+	"sort"
+)
+
+const (
+	// SchemaVersion is the version of metadata.yaml written by this package.
+	SchemaVersion = 2
+
+	FormatFrontMatter = "frontmatter"
+)
+
+type Metadata struct {
+	Schema      int
+	SubDomain   string
+	BaseURL     string
+	Format      string                  `yaml:",omitempty"`
+	Categories  map[string]*Category    `yaml:",omitempty"`
+	Versions    map[string]*VersionDocs `yaml:",omitempty"`
+	CustomPages map[string]*CustomPage  `yaml:",omitempty"`
+	root        *Metadata
+}
+
+type VersionDocs struct {
+	Categories map[string]*Category
+}
+
+type Category struct {
+	ID   string
+	Docs map[string]*Doc
+}
+
+// Doc is a tracked doc. ID, Type and Order are unknown for docs tracked
+// before schema 2 until they are synchronized again.
+type Doc struct {
+	ID      string `yaml:",omitempty"`
+	Title   string
+	Excerpt string
+	Hidden  bool
+	Type    string `yaml:",omitempty"`
+	Order   int    `yaml:",omitempty"`
+	// Parent is the slug of the parent doc, whose folder holds this doc
+	Parent string `yaml:",omitempty"`
+	Base   *Base  `yaml:",omitempty"`
+}
+
+// Base is the state of a doc when it was last synchronized with ReadMe,
+// whose body is stored separately and verified by Hash.
+type Base struct {
+	Title     string
+	Excerpt   string
+	Hidden    bool
+	Type      string `yaml:",omitempty"`
+	Order     int    `yaml:",omitempty"`
+	ParentDoc string `yaml:",omitempty"`
+	Hash      string
+}
+
+type CustomPage struct {
+	Title    string
+	HTMLMode bool
+	Hidden   bool
+}
+
+func NewMetadata(subDomain string) *Metadata {
+	return &Metadata{
+		Schema:     SchemaVersion,
+		SubDomain:  subDomain,
+		Categories: make(map[string]*Category),
+	}
+}
+
+// Version returns a view of the docs of a project version sharing the
+// underlying maps, or m itself for the default version.
+func (m *Metadata) Version(version string) *Metadata {
+	if version == "" {
+		return m
+	}
+	if m.Versions == nil {
+		m.Versions = make(map[string]*VersionDocs)
+	}
+	v := m.Versions[version]
+	if v == nil {
+		v = &VersionDocs{
+			Categories: make(map[string]*Category),
+		}
+		m.Versions[version] = v
+	}
+	return &Metadata{
+		Schema:     m.Schema,
+		SubDomain:  m.SubDomain,
+		BaseURL:    m.BaseURL,
+		Format:     m.Format,
+		Categories: v.Categories,
+		root:       m,
+	}
+}
+
+func (m *Metadata) Root() *Metadata {
+	if m.root != nil {
+		return m.root
+	}
+	return m
+}
+
+func (m *Metadata) Doc(slug string) (string, *Category, *Doc) {
+	for catKey, cat := range m.Categories {
+		for docKey, doc := range cat.Docs {
+			if docKey == slug {
+				return catKey, cat, doc
+			}
+		}
+	}
+	return "", nil, nil
+}
+
+// DocByID returns the slug and metadata of the doc with id, or "" and nil.
+func (m *Metadata) DocByID(id string) (string, *Doc) {
+	if id == "" {
+		return "", nil
+	}
+	for _, cat := range m.Categories {
+		for slug, doc := range cat.Docs {
+			if doc.ID == id {
+				return slug, doc
+			}
+		}
+	}
+	return "", nil
+}
+
+// Parents returns the slugs of the ancestors of a doc in a category,
+// outermost first.
+func (m *Metadata) Parents(cat, slug string) []string {
+	res := make([]string, 0)
+	catMeta := m.Categories[cat]
+	if catMeta == nil {
+		return res
+	}
+	seen := map[string]bool{slug: true}
+	for doc := catMeta.Docs[slug]; doc != nil && doc.Parent != "" && !seen[doc.Parent]; doc = catMeta.Docs[doc.Parent] {
+		seen[doc.Parent] = true
+		res = append([]string{doc.Parent}, res...)
+	}
+	return res
+}
+
+// ForEachDoc calls fn for every doc ordered by category and slug, stopping
+// at the first error.
+func (m *Metadata) ForEachDoc(fn func(cat string, catMeta *Category, slug string, doc *Doc) error) error {
+	for _, catKey := range m.CategoryKeys() {
+		cat := m.Categories[catKey]
+		for _, docKey := range cat.DocKeys() {
+			err := fn(catKey, cat, docKey, cat.Docs[docKey])
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// AddCategory returns the category of slug, adding it if not found.
+func (m *Metadata) AddCategory(slug, id string) *Category {
+	if m.Categories == nil {
+		m.Categories = make(map[string]*Category)
+	}
+	cat := m.Categories[slug]
+	if cat == nil {
+		cat = &Category{
+			ID:   id,
+			Docs: make(map[string]*Doc),
+		}
+		m.Categories[slug] = cat
+	}
+	if cat.ID == "" {
+		cat.ID = id
+	}
+	if cat.Docs == nil {
+		cat.Docs = make(map[string]*Doc)
+	}
+	return cat
+}
+
+func (m *Metadata) CategoryKeys() []string {
+	keys := make([]string, 0)
+	for k := range m.Categories {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func (c *Category) DocKeys() []string {
+	keys := make([]string, 0)
+	for k := range c.Docs {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
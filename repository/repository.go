@@ -0,0 +1,178 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	MetadataFilename = "metadata.yaml"
+	baseDir          = ".base"
+)
+
+var errFound = errors.New("found")
+
+// Repository is a local copy of the docs of a ReadMe project, rooted at Dir
+// with docs of a non-default Version in a subfolder.
+type Repository struct {
+	Dir     string
+	Version string
+}
+
+func New(dir, version string) *Repository {
+	return &Repository{
+		Dir:     dir,
+		Version: version,
+	}
+}
+
+func (r *Repository) MetadataPath() string {
+	return filepath.Join(r.Dir, MetadataFilename)
+}
+
+func (r *Repository) VersionPath() string {
+	return filepath.Join(r.Dir, r.Version)
+}
+
+func (r *Repository) CategoryPath(cat string) string {
+	return filepath.Join(r.VersionPath(), cat)
+}
+
+// DocPath returns the path of a doc nested in the folders of its parents,
+// outermost first.
+func (r *Repository) DocPath(cat string, parents []string, slug string) string {
+	elems := append([]string{r.VersionPath(), cat}, parents...)
+	return filepath.Join(append(elems, fmt.Sprintf("%s.md", slug))...)
+}
+
+// FindDoc searches the folder of a category for a doc, returning its path
+// and the slugs of its parents from the folders it is nested in, or an
+// error satisfying os.IsNotExist if there is none.
+func (r *Repository) FindDoc(cat, slug string) (string, []string, error) {
+	root := r.CategoryPath(cat)
+	name := fmt.Sprintf("%s.md", slug)
+	found := ""
+	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			if path != root && strings.HasPrefix(info.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if info.Name() == name {
+			found = path
+			return errFound
+		}
+		return nil
+	})
+	if err != nil && err != errFound {
+		return "", nil, err
+	}
+	if found == "" {
+		return "", nil, &os.PathError{Op: "find", Path: r.DocPath(cat, nil, slug), Err: os.ErrNotExist}
+	}
+	return found, DocParents(root, found), nil
+}
+
+// DocParents returns the slugs of the parents of the doc at path from the
+// folders between the category folder root and the doc.
+func DocParents(root, path string) []string {
+	rel, err := filepath.Rel(root, filepath.Dir(path))
+	if err != nil || rel == "." {
+		return []string{}
+	}
+	return strings.Split(filepath.ToSlash(rel), "/")
+}
+
+func (r *Repository) BasePath(slug string) string {
+	return filepath.Join(r.Dir, baseDir, r.Version, fmt.Sprintf("%s.md", slug))
+}
+
+// Load reads the metadata of the repository, returning an error satisfying
+// os.IsNotExist if there is none yet.
+func (r *Repository) Load() (*Metadata, error) {
+	data, err := ioutil.ReadFile(r.MetadataPath())
+	if err != nil {
+		return nil, err
+	}
+	meta := &Metadata{}
+	err = yaml.Unmarshal(data, meta)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %s", r.MetadataPath(), err.Error())
+	}
+	err = upgrade(meta)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %s", r.MetadataPath(), err.Error())
+	}
+	return meta, nil
+}
+
+// Save writes the whole metadata of the repository atomically, so that an
+// interrupted write never leaves a truncated metadata.yaml.
+func (r *Repository) Save(meta *Metadata) error {
+	root := meta.Root()
+	root.Schema = SchemaVersion
+	data, err := yaml.Marshal(root)
+	if err != nil {
+		return err
+	}
+	return WriteFile(r.MetadataPath(), data)
+}
+
+func upgrade(meta *Metadata) error {
+	if meta.Schema > SchemaVersion {
+		return fmt.Errorf("unsupported schema version %d, please upgrade this tool", meta.Schema)
+	}
+	if meta.Schema < 1 {
+		// schema 0 had no version field but the same layout
+		meta.Schema = 1
+	}
+	if meta.Schema < 2 {
+		// schema 2 added the hierarchy of docs, left empty until the next
+		// pull fills it in
+		meta.Schema = 2
+	}
+	return nil
+}
+
+// WriteFile writes data to a temporary file in the same folder and renames
+// it to path.
+func WriteFile(path string, data []byte) error {
+	dir := filepath.Dir(path)
+	err := os.MkdirAll(dir, os.ModePerm)
+	if err != nil {
+		return err
+	}
+	f, err := ioutil.TempFile(dir, "."+filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	tmp := f.Name()
+	_, err = f.Write(data)
+	if err == nil {
+		err = f.Sync()
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Chmod(tmp, 0644)
+	}
+	if err == nil {
+		err = os.Rename(tmp, path)
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,151 @@
+package repository
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestLoadSave(t *testing.T) {
+	r := New(t.TempDir(), "")
+	_, err := r.Load()
+	if !os.IsNotExist(err) {
+		t.Fatalf("got %v before saving, want not exist", err)
+	}
+	meta := NewMetadata("example")
+	meta.BaseURL = "https://example.readme.io"
+	meta.Format = FormatFrontMatter
+	cat := meta.AddCategory("guides", "c1")
+	cat.Docs["intro"] = &Doc{ID: "d1", Title: "Intro", Order: 2, Base: &Base{Title: "Intro", Hash: "abc"}}
+	cat.Docs["setup"] = &Doc{ID: "d2", Title: "Setup", Parent: "intro"}
+	meta.Version("2.0").AddCategory("api", "c2").Docs["auth"] = &Doc{Title: "Auth", Hidden: true}
+	err = r.Save(meta)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := r.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, meta) {
+		t.Errorf("got %+v, want %+v", got, meta)
+	}
+	if parents := got.Parents("guides", "setup"); !reflect.DeepEqual(parents, []string{"intro"}) {
+		t.Errorf("got parents %v", parents)
+	}
+}
+
+func TestUpgrade(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		wantErr bool
+	}{
+		{"schema 0", "subdomain: example\ncategories:\n  guides:\n    docs:\n      intro:\n        title: Intro\n", false},
+		{"schema 1", "schema: 1\nsubdomain: example\ncategories:\n  guides:\n    docs:\n      intro:\n        title: Intro\n", false},
+		{"current", "schema: 2\nsubdomain: example\ncategories:\n  guides:\n    docs:\n      intro:\n        title: Intro\n", false},
+		{"newer", "schema: 3\nsubdomain: example\n", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := New(t.TempDir(), "")
+			err := ioutil.WriteFile(r.MetadataPath(), []byte(tt.data), 0644)
+			if err != nil {
+				t.Fatal(err)
+			}
+			meta, err := r.Load()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("got %v, want error %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if meta.Schema != SchemaVersion {
+				t.Errorf("got schema %d, want %d", meta.Schema, SchemaVersion)
+			}
+			doc := meta.Categories["guides"].Docs["intro"]
+			if doc == nil || doc.Title != "Intro" || doc.ID != "" || doc.Parent != "" {
+				t.Errorf("got doc %+v", doc)
+			}
+		})
+	}
+}
+
+func TestFindDoc(t *testing.T) {
+	r := New(t.TempDir(), "2.0")
+	for _, path := range []string{
+		r.DocPath("guides", nil, "intro"),
+		r.DocPath("guides", []string{"intro"}, "setup"),
+		filepath.Join(r.CategoryPath("guides"), ".drafts", "draft.md"),
+	} {
+		err := WriteFile(path, []byte("body\n"))
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	tests := []struct {
+		slug     string
+		wantPath string
+		parents  []string
+	}{
+		{"intro", filepath.Join(r.Dir, "2.0", "guides", "intro.md"), []string{}},
+		{"setup", filepath.Join(r.Dir, "2.0", "guides", "intro", "setup.md"), []string{"intro"}},
+		{"draft", "", nil},
+		{"missing", "", nil},
+	}
+	for _, tt := range tests {
+		path, parents, err := r.FindDoc("guides", tt.slug)
+		if tt.wantPath == "" {
+			if !os.IsNotExist(err) {
+				t.Errorf("%s: got %s, %v, want not exist", tt.slug, path, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if path != tt.wantPath || !reflect.DeepEqual(parents, tt.parents) {
+			t.Errorf("%s: got %s %v, want %s %v", tt.slug, path, parents, tt.wantPath, tt.parents)
+		}
+	}
+	_, _, err := r.FindDoc("reference", "intro")
+	if !os.IsNotExist(err) {
+		t.Errorf("got %v for a missing category, want not exist", err)
+	}
+}
+
+func TestWriteFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "doc.md")
+	err := ioutil.WriteFile(path, []byte("old body, longer than the new one\n"), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = WriteFile(path, []byte("new\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "new\n" {
+		t.Errorf("got %q", data)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0644 {
+		t.Errorf("got mode %s, want 0644", info.Mode())
+	}
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 1 {
+		t.Errorf("got %d files, want no temporary file left", len(files))
+	}
+}